@@ -0,0 +1,166 @@
+package health
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// actuatorMediaType is the media type of the Spring Boot Actuator health
+// endpoint, returned when requested by the client.
+const actuatorMediaType = "application/vnd.spring-boot.actuator.v3+json"
+
+// ShowDetails determines whether the components, and their details, are included
+// in a response in the Spring Boot Actuator format, mirroring the show-details
+// property of the actuator health endpoint.
+type ShowDetails func(r *http.Request) bool
+
+// ShowDetailsNever never includes the components, so the response only contains
+// the overall status.
+func ShowDetailsNever() ShowDetails {
+	return func(r *http.Request) bool {
+		return false
+	}
+}
+
+// ShowDetailsAlways always includes the components and their details.
+func ShowDetailsAlways() ShowDetails {
+	return func(r *http.Request) bool {
+		return true
+	}
+}
+
+// ShowDetailsWhenAuthorized includes the components and their details only if
+// the authorized function returns true for the request.
+func ShowDetailsWhenAuthorized(authorized func(r *http.Request) bool) ShowDetails {
+	return ShowDetails(authorized)
+}
+
+// WithActuatorFormat serves the health endpoint in the format of the Spring Boot
+// Actuator health endpoint rather than the default JSON format, for tools such as
+// Spring Boot Admin which expect it.
+//
+//	{
+//	  "status": "UP",
+//	  "components": {
+//	    "database": {
+//	      "status": "UP",
+//	      "details": {
+//	        "latency": "1.208ms"
+//	      }
+//	    }
+//	  },
+//	  "groups": ["liveness", "readiness"]
+//	}
+//
+// Whether the components are included is determined by show, which defaults to
+// ShowDetailsNever, as with Spring Boot. The groups of the components are listed
+// in the response of the overall status, and when combined with the
+// WithComponentRoutes option each group is served beneath the base path, such as
+// /actuator/health/readiness.
+func WithActuatorFormat(show ShowDetails) HandlerOption {
+	return func(conf *handlerConfig) {
+		if show == nil {
+			show = ShowDetailsNever()
+		}
+		conf.encoder = actuatorEncoder{show: show}
+	}
+}
+
+// actuatorEncoder writes the responses of the health endpoint in the format of
+// the Spring Boot Actuator health endpoint.
+type actuatorEncoder struct {
+	show ShowDetails
+}
+
+type actuatorComponent struct {
+	Status  Status         `json:"status"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type actuatorResponse struct {
+	Status     Status                       `json:"status"`
+	Components map[string]actuatorComponent `json:"components,omitempty"`
+	Groups     []string                     `json:"groups,omitempty"`
+}
+
+// encodeSnapshot writes the Snapshot as the Spring Boot Actuator response of the
+// health endpoint.
+func (e actuatorEncoder) encodeSnapshot(w http.ResponseWriter, r *http.Request, snapshot Snapshot, conf handlerConfig) {
+	res := actuatorResponse{
+		Status: snapshot.Status,
+	}
+	if e.show(r) {
+		res.Components = make(map[string]actuatorComponent, len(snapshot.Components))
+		for _, status := range snapshot.Components {
+			res.Components[status.Name] = e.component(status, conf, true)
+		}
+	}
+	// Like Spring Boot, the groups are only listed in the response of the
+	// overall status.
+	if conf.group == "" {
+		for _, status := range snapshot.Components {
+			for _, group := range status.groups() {
+				if !slices.Contains(res.Groups, group) {
+					res.Groups = append(res.Groups, group)
+				}
+			}
+		}
+		slices.Sort(res.Groups)
+	}
+	e.write(w, r, snapshot.Status, conf, res)
+}
+
+// encodeComponent writes the status of a single component as the Spring Boot
+// Actuator response of the health endpoint.
+func (e actuatorEncoder) encodeComponent(w http.ResponseWriter, r *http.Request, status ComponentStatus, conf handlerConfig) {
+	e.write(w, r, status.Status, conf, e.component(status, conf, e.show(r)))
+}
+
+// component returns the Spring Boot Actuator representation of the status of the
+// component, which carries the message, error, latency, and time of the last
+// check of the component as details.
+func (e actuatorEncoder) component(status ComponentStatus, conf handlerConfig, details bool) actuatorComponent {
+	c := actuatorComponent{
+		Status: status.Status,
+	}
+	if !details {
+		return c
+	}
+
+	status = conf.redact(status)
+	c.Details = maps.Clone(status.Details)
+	if c.Details == nil {
+		c.Details = make(map[string]any)
+	}
+	if status.Message != "" {
+		c.Details["message"] = status.Message
+	}
+	if status.Error != "" {
+		c.Details["error"] = status.Error
+	}
+	if status.Latency > 0 {
+		c.Details["latency"] = status.Latency.String()
+	}
+	if !status.LastChecked.IsZero() {
+		c.Details["lastChecked"] = status.LastChecked.Format(time.RFC3339Nano)
+	}
+	if len(c.Details) == 0 {
+		c.Details = nil
+	}
+	return c
+}
+
+func (e actuatorEncoder) write(w http.ResponseWriter, r *http.Request, status Status, conf handlerConfig, v any) {
+	contentType := "application/json;charset=utf-8"
+	if strings.Contains(r.Header.Get("Accept"), actuatorMediaType) {
+		contentType = actuatorMediaType
+	}
+	if !conf.writeHeader(w, r, status, contentType) {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(v)
+}
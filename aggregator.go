@@ -0,0 +1,199 @@
+package health
+
+import (
+	"slices"
+)
+
+// Aggregator determines the overall status of the application from the status
+// of each component. The Aggregator of a Health instance is configured using the
+// WithAggregator option, and is used to determine the overall status returned by
+// Status, Snapshot, the HTTP handlers, and the Prometheus metrics.
+type Aggregator interface {
+	Aggregate(components []ComponentStatus) Status
+}
+
+// AggregatorFunc is an adapter allowing an ordinary function to be used as an
+// Aggregator.
+type AggregatorFunc func(components []ComponentStatus) Status
+
+// Aggregate calls f(components).
+func (f AggregatorFunc) Aggregate(components []ComponentStatus) Status {
+	return f(components)
+}
+
+var defaultAggregator = AggregatorFunc(aggregate)
+
+// DefaultAggregator returns the Aggregator used unless another is configured.
+// If a critical component is down the overall status is down, and otherwise if
+// the status of a critical component is unknown the overall status is unknown.
+// If a major or minor component is down or unknown, or any component is
+// degraded, the overall status is degraded. Otherwise, the overall status is up.
+// Informational components never affect the overall status.
+func DefaultAggregator() Aggregator {
+	return defaultAggregator
+}
+
+// aggregate determines the overall status from the status of each component.
+func aggregate(statuses []ComponentStatus) Status {
+	status := StatusUp
+	for _, component := range affecting(statuses) {
+		// A critical component contributes its own status to the overall status,
+		// so if a critical component is down the overall status is down, and if
+		// its status is not yet known the overall status is unknown.
+		contribution := component.Status
+		// A component that is not critical can at most degrade the overall
+		// status, regardless of how severe its own status is.
+		if component.severity() != SeverityCritical && contribution.severity() > StatusDegraded.severity() {
+			contribution = StatusDegraded
+		}
+		status = worst(status, contribution)
+	}
+	return status
+}
+
+// affecting returns the components that affect the overall status, which is
+// every component other than informational components.
+func affecting(statuses []ComponentStatus) []ComponentStatus {
+	components := make([]ComponentStatus, 0, len(statuses))
+	for _, component := range statuses {
+		if component.severity() != SeverityInfo {
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+// WorstOfAggregator returns an Aggregator which reports the worst status of any
+// component regardless of its severity, where down is worse than unknown, which
+// is worse than degraded, which is worse than up.
+//
+// As with every provided Aggregator, informational components never affect the
+// overall status.
+func WorstOfAggregator() Aggregator {
+	return AggregatorFunc(func(components []ComponentStatus) Status {
+		status := StatusUp
+		for _, component := range affecting(components) {
+			status = worst(status, component.Status)
+		}
+		return status
+	})
+}
+
+// QuorumAggregator returns an Aggregator which requires at least n of the named
+// components, such as the replicas of a clustered dependency, to be up. If fewer
+// than n of the components are up or degraded the overall status is down, and
+// otherwise if any of the components is not up the overall status is degraded.
+// If no names are provided the quorum applies to every component.
+//
+// Only the named components being aggregated are counted, so when a handler is
+// restricted to a group, or components are excluded, the quorum is reduced to
+// the number of named components present, and no quorum is required of a group
+// without any of the named components.
+//
+// The status of the components not named is determined by DefaultAggregator,
+// with the overall status being the worse of the two.
+func QuorumAggregator(n int, names ...string) Aggregator {
+	return AggregatorFunc(func(components []ComponentStatus) Status {
+		var members, others []ComponentStatus
+		for _, component := range affecting(components) {
+			if len(names) == 0 || slices.Contains(names, component.Name) {
+				members = append(members, component)
+			} else {
+				others = append(others, component)
+			}
+		}
+		if len(members) == 0 {
+			return aggregate(others)
+		}
+
+		available, up := 0, 0
+		for _, component := range members {
+			switch component.Status {
+			case StatusUp:
+				up++
+				available++
+			case StatusDegraded:
+				available++
+			}
+		}
+		status := StatusUp
+		switch {
+		case available < min(n, len(members)):
+			status = StatusDown
+		case up < len(members):
+			status = StatusDegraded
+		}
+		return worst(status, aggregate(others))
+	})
+}
+
+// PercentageAggregator returns an Aggregator which determines the overall status
+// from the fraction of components that are up, between 0 and 1. If at least the
+// up fraction of components are up the overall status is up, and otherwise if at
+// least the degraded fraction of components are up the overall status is
+// degraded. Otherwise, the overall status is down.
+func PercentageAggregator(up, degraded float64) Aggregator {
+	return AggregatorFunc(func(components []ComponentStatus) Status {
+		components = affecting(components)
+		if len(components) == 0 {
+			return StatusUp
+		}
+		n := 0
+		for _, component := range components {
+			if component.Status == StatusUp {
+				n++
+			}
+		}
+		return threshold(float64(n)/float64(len(components)), up, degraded)
+	})
+}
+
+// WeightedAggregator returns an Aggregator which determines the overall status
+// from a weighted score, between 0 and 1, of the components. A component that is
+// up scores 1, a component that is degraded scores 0.5, and any other component
+// scores 0. The score of each component is weighted by the weight for its name,
+// or 1 if no weight is provided. If the score is at least up the overall status
+// is up, and otherwise if the score is at least degraded the overall status is
+// degraded. Otherwise, the overall status is down.
+func WeightedAggregator(weights map[string]float64, up, degraded float64) Aggregator {
+	return AggregatorFunc(func(components []ComponentStatus) Status {
+		var score, total float64
+		for _, component := range affecting(components) {
+			weight, ok := weights[component.Name]
+			if !ok {
+				weight = 1
+			}
+			total += weight
+			switch component.Status {
+			case StatusUp:
+				score += weight
+			case StatusDegraded:
+				score += weight / 2
+			}
+		}
+		if total == 0 {
+			return StatusUp
+		}
+		return threshold(score/total, up, degraded)
+	})
+}
+
+// threshold determines the status from a score between 0 and 1.
+func threshold(score, up, degraded float64) Status {
+	switch {
+	case score >= up:
+		return StatusUp
+	case score >= degraded:
+		return StatusDegraded
+	default:
+		return StatusDown
+	}
+}
+
+// worst returns the worse of the two statuses according to their severity.
+func worst(a, b Status) Status {
+	if b.severity() > a.severity() {
+		return b
+	}
+	return a
+}
@@ -2,9 +2,118 @@ package health
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 )
 
+// ErrCheckTimeout indicates a health check did not complete within the Timeout
+// of the component. Checks that do not respect the cancellation of their context
+// are abandoned once the Timeout elapses.
+var ErrCheckTimeout = errors.New("health: check timed out")
+
+// errCheckStillRunning is the reason a component is not checked while a check
+// abandoned after exceeding its Timeout has not returned.
+var errCheckStillRunning = errors.New("previous check has not returned")
+
+// PanicError is the error recorded for a health check that panicked. The panic
+// is recovered so a faulty check cannot crash the application.
+type PanicError struct {
+	// Value passed to panic.
+	Value any
+
+	// Stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("health: check panicked: %v", e.Value)
+}
+
+// FailureReason classifies why a health check failed.
+type FailureReason string
+
+const (
+	// FailureError indicates the check reported the component as down.
+	FailureError FailureReason = "error"
+	// FailureTimeout indicates the check did not complete within the Timeout
+	// of the component.
+	FailureTimeout FailureReason = "timeout"
+	// FailurePanic indicates the check panicked.
+	FailurePanic FailureReason = "panic"
+	// FailureDependency indicates the component was not checked because a
+	// component it depends on is unavailable.
+	FailureDependency FailureReason = "dependency"
+)
+
+// failureReason classifies the Result of a health check. A Result that isn't
+// down has no failure reason, unless the component wasn't checked because a
+// dependency is unavailable.
+func failureReason(result Result) FailureReason {
+	var depErr *DependencyError
+	if errors.As(result.Error, &depErr) {
+		return FailureDependency
+	}
+	if result.Status != StatusDown {
+		return ""
+	}
+	var panicErr *PanicError
+	switch {
+	case errors.As(result.Error, &panicErr):
+		return FailurePanic
+	case errors.Is(result.Error, ErrCheckTimeout):
+		return FailureTimeout
+	default:
+		return FailureError
+	}
+}
+
 // CheckFunc is a function type that checks/verifies the health of a component
 // and or service. If an error is returned, the component/service is considered
 // unhealthy and down.
 type CheckFunc func(ctx context.Context) error
+
+// ResultCheck adapts the CheckFunc to a ResultCheckFunc. If the CheckFunc returns
+// an error the Result has a status of down and the error, otherwise the Result
+// has a status of up.
+func (f CheckFunc) ResultCheck() ResultCheckFunc {
+	return func(ctx context.Context) Result {
+		if err := f(ctx); err != nil {
+			return Result{
+				Status: StatusDown,
+				Error:  err,
+			}
+		}
+		return Result{Status: StatusUp}
+	}
+}
+
+// ResultCheckFunc is a function type that checks/verifies the health of a
+// component and or service and returns a detailed Result. Unlike CheckFunc, a
+// ResultCheckFunc can report a component as degraded and attach additional
+// context about the state of the component.
+type ResultCheckFunc func(ctx context.Context) Result
+
+// Result is the outcome of a health check.
+type Result struct {
+	// Status of the component. If the Status is empty the component is
+	// considered up. A Status that has not been registered, see RegisterStatus,
+	// is recorded as down with an error wrapping ErrInvalidStatus.
+	Status Status
+
+	// Human-readable message describing the state of the component.
+	Message string
+
+	// Error that caused the check to fail, if any.
+	Error error
+
+	// Arbitrary details about the state of the component, such as the version
+	// of a database or the number of open connections. Details are included in
+	// the ComponentStatus and the JSON response of the health endpoint, so they
+	// must be JSON serializable and should not contain sensitive information.
+	Details map[string]any
+
+	// Latency observed while communicating with the component. If the Latency
+	// is zero the time taken to execute the check is used.
+	Latency time.Duration
+}
@@ -3,18 +3,24 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand"
 	"net/http"
+	"runtime/debug"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
 // Component represents a single component that can be checked for health.
 type Component struct {
 
-	// Name or identifier of the component. Each component should have a unique
-	// name. Otherwise, the response from ServeHTTP will be not be particularly
-	// helpful in identifying which component is in a bad state.
+	// Name or identifier of the component. Each component must have a unique,
+	// non-empty name.
 	Name string
 
 	// Determines if the component is critical to the overall health and
@@ -22,70 +28,761 @@ type Component struct {
 	// it fails its check, the overall status of the system will be down.
 	Critical bool
 
+	// Severity of the component being unavailable. If not set, the severity is
+	// SeverityCritical if Critical is true, and SeverityMajor otherwise. If both
+	// are set, Critical must agree with the Severity. Once registered, Critical
+	// is true if and only if the Severity is SeverityCritical.
+	Severity Severity
+
 	// Timeout for the health check. If the health check takes longer than the
 	// timeout, the check is considered to have failed. The default value is
-	// 5 seconds.
+	// 5 seconds, or half the Interval if the Interval is less than 10 seconds.
 	Timeout time.Duration
 
 	// Interval between health checks. If the interval is set to zero, a default
 	// interval of 15 seconds will be used. The Interval must be greater than
-	// the Timeout.
+	// the Timeout if set, otherwise registering the component fails.
 	Interval time.Duration
 
 	// The health check of the component.
 	//
-	// A nil Check will cause a panic.
+	// Either Check or ResultCheck must be non-nil, otherwise registering the
+	// component fails.
 	Check CheckFunc
 
-	status Status
+	// The health check of the component returning a detailed Result. If both
+	// Check and ResultCheck are non-nil, ResultCheck takes precedence.
+	ResultCheck ResultCheckFunc
+
+	// Number of consecutive failed checks required before the component is
+	// considered down. Until the threshold is reached the component retains its
+	// previous status, which prevents a single failed check, such as a timeout,
+	// from taking the component down. The default value is 1.
+	//
+	// The threshold does not apply to the first check of the component, since
+	// there is no previous status to retain.
+	FailureThreshold int
+
+	// Number of consecutive successful checks required before a component that
+	// is down is considered to have recovered. The default value is 1.
+	SuccessThreshold int
+
+	// If enabled, the component is considered degraded while failed checks are
+	// accumulating but the FailureThreshold has not yet been reached, rather
+	// than retaining its previous status.
+	DegradeOnFailure bool
+
+	// Interval between health checks while the component is down. This can be
+	// used to check more frequently while a component is down to detect recovery
+	// quickly. If zero, Interval is used.
+	UnhealthyInterval time.Duration
+
+	// If set, the interval between health checks while the component is down is
+	// doubled after each consecutive failed check since the component went down,
+	// starting from the UnhealthyInterval, or Interval if not set, up to
+	// MaxBackoff. This avoids hammering a dependency that is experiencing an
+	// outage.
+	MaxBackoff time.Duration
+
+	// Fraction of the interval between health checks, at least 0 and less than
+	// 1, by which each interval is randomly lengthened or shortened. Jitter
+	// prevents a fleet of instances from checking a shared dependency in
+	// lockstep. The default is no jitter.
+	Jitter float64
+
+	// Duration the result of a check is reused when the component is checked
+	// on demand, see WithOnDemandChecks. If zero, the component is checked each
+	// time its status is requested, although concurrent requests still share a
+	// single check.
+	CacheTTL time.Duration
+
+	// Names of the components this component depends on. While a dependency is
+	// down the component is not checked, and its status is reported as unknown
+	// with a DependencyError, since checking it would only fail for the same
+	// reason. Dependencies are resolved among the components registered with the
+	// same Health instance, and must not form a cycle.
+	DependsOn []string
+
+	// Groups the component belongs to, such as GroupLiveness, GroupReadiness,
+	// and GroupStartup, or arbitrary tags. Handlers can be restricted to the
+	// components of a group with the WithGroup option. A component that doesn't
+	// specify any groups belongs to the readiness group.
+	Groups []string
+
+	probe    ResultCheckFunc
+	severity Severity
+
+	// defaultTimeout is true if the Timeout was not set and has been defaulted
+	// from the Interval.
+	defaultTimeout bool
+	passive        *PassiveHandle
+	state          *componentState
+
+	// ctx is the context of the checks of the component, which is cancelled by
+	// stop when the component stops being monitored.
+	ctx  context.Context
+	stop context.CancelFunc
+
+	// logger, if set, is used to log the results of checks.
+	logger               *slog.Logger
+	repeatedFailureLevel slog.Level
+
+	// dependency, if set, returns the registered component with the given name,
+	// or nil if no such component is registered.
+	dependency func(name string) *Component
+
+	// onChange, if set, is called when a check changes the status of the
+	// component.
+	onChange func(c *Component, previous, current Status, result Result)
+
+	// inflight, if set, tracks the goroutines executing checks, including
+	// checks that have been abandoned but have not yet returned.
+	inflight *sync.WaitGroup
+}
+
+// componentState holds the mutable state of a Component. The state is written
+// by the monitor goroutine and read concurrently by Status, Snapshot, the HTTP
+// handler and the Prometheus collector, so all access is guarded by mu.
+type componentState struct {
+	mu                   sync.RWMutex
+	status               Status
+	result               Result
+	failure              FailureReason
+	lastChecked          time.Time
+	lastSuccess          time.Time
+	duration             time.Duration
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	attempts             int
+
+	// downFailures is the number of consecutive failed checks since the
+	// component went down, which determines the backoff between checks.
+	downFailures int
+
+	// abandoned, if set, is closed when a check that was abandoned because it
+	// didn't return within the Timeout finally returns. No further checks are
+	// started until then, so checks that never return don't accumulate.
+	abandoned chan struct{}
+
+	// flight, if set, is closed when the check of the component in progress
+	// completes. The check is executed with flightCtx, and once flightCtx is
+	// done, such as when the component is updated, the result of the check is
+	// discarded so it is not shared with other callers.
+	flight    chan struct{}
+	flightCtx context.Context
+}
+
+var (
+	// ErrEmptyName indicates a component does not have a name.
+	ErrEmptyName = errors.New("name must not be empty")
+
+	// ErrDuplicateName indicates a component has the same name as a component
+	// that is already registered.
+	ErrDuplicateName = errors.New("name is already registered")
+
+	// ErrNilCheck indicates a component has neither a Check nor ResultCheck.
+	ErrNilCheck = errors.New("check must not be nil")
+
+	// ErrInvalidTimeout indicates the Timeout or Interval of a component is
+	// negative, or the Timeout is not less than the Interval.
+	ErrInvalidTimeout = errors.New("timeout must be less than interval")
+
+	// ErrInvalidConfig indicates a component has an invalid threshold, backoff,
+	// or jitter configuration.
+	ErrInvalidConfig = errors.New("invalid configuration")
+)
+
+// ValidationError is returned when a component is misconfigured. The underlying
+// error is one of ErrEmptyName, ErrDuplicateName, ErrNilCheck, ErrInvalidTimeout,
+// ErrDependencyCycle or ErrInvalidConfig and can be checked using errors.Is.
+type ValidationError struct {
+	// Name of the misconfigured component.
+	Component string
+
+	// Reason the component is misconfigured.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("health: invalid component %q: %s", e.Component, e.Err)
 }
 
-func (c *Component) init() {
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// init validates the component, applies default values for any configuration
+// that hasn't been set, and initializes the state of the component.
+func (c *Component) init() error {
+	invalid := func(err error) error {
+		return &ValidationError{Component: c.Name, Err: err}
+	}
+
 	if strings.TrimSpace(c.Name) == "" {
-		c.Name = "MISSING NAME"
+		return invalid(ErrEmptyName)
+	}
+	if c.Check == nil && c.ResultCheck == nil && c.passive == nil {
+		return invalid(ErrNilCheck)
+	}
+	if c.Timeout < 0 || c.Interval < 0 {
+		return invalid(ErrInvalidTimeout)
+	}
+	if c.FailureThreshold < 0 || c.SuccessThreshold < 0 {
+		return invalid(fmt.Errorf("%w: thresholds must not be negative", ErrInvalidConfig))
 	}
-	if c.Timeout == 0 {
-		c.Timeout = 5 * time.Second
+	if c.UnhealthyInterval < 0 || c.MaxBackoff < 0 {
+		return invalid(fmt.Errorf("%w: unhealthy interval and max backoff must not be negative", ErrInvalidConfig))
 	}
+	if c.CacheTTL < 0 {
+		return invalid(fmt.Errorf("%w: cache ttl must not be negative", ErrInvalidConfig))
+	}
+	for _, dep := range c.DependsOn {
+		if strings.TrimSpace(dep) == "" {
+			return invalid(fmt.Errorf("%w: dependency name must not be empty", ErrInvalidConfig))
+		}
+	}
+	severity, err := resolveSeverity(c.Severity, c.Critical)
+	if err != nil {
+		return invalid(err)
+	}
+	c.severity = severity
+	c.Critical = severity == SeverityCritical
+	if c.Jitter < 0 || c.Jitter >= 1 {
+		return invalid(fmt.Errorf("%w: jitter must be at least 0 and less than 1", ErrInvalidConfig))
+	}
+
 	if c.Interval == 0 {
 		c.Interval = 15 * time.Second
 	}
+	c.defaultTimeout = c.Timeout == 0
+	if c.defaultTimeout {
+		c.Timeout = min(5*time.Second, c.Interval/2)
+	}
 	if c.Timeout >= c.Interval {
-		c.Interval = c.Timeout + 1*time.Second
-		fmt.Println("Timeout was greater than or equal to interval. Setting interval to timeout + 1 second.")
+		return invalid(fmt.Errorf("%w: timeout %s, interval %s", ErrInvalidTimeout, c.Timeout, c.Interval))
 	}
-	c.status = StatusUp
+	if c.FailureThreshold == 0 {
+		c.FailureThreshold = 1
+	}
+	if c.SuccessThreshold == 0 {
+		c.SuccessThreshold = 1
+	}
+
+	c.probe = c.ResultCheck
+	if c.probe == nil && c.Check != nil {
+		c.probe = c.Check.ResultCheck()
+	}
+	c.state = &componentState{
+		status: StatusUnknown,
+		result: Result{Status: StatusUnknown},
+	}
+	return nil
 }
 
-// monitor performs a healthcheck on the component at regular intervals and
-// updates the status of the component.
+// setStatus updates the status of the component without recording a check.
+func (c *Component) setStatus(status Status) {
+	c.state.mu.Lock()
+	c.state.status = status
+	c.state.result = Result{Status: status}
+	c.state.failure = ""
+	c.state.mu.Unlock()
+}
+
+// record updates the state of the component from the Result of a check which
+// completed at the given time and took the given duration to execute.
+//
+// A check is considered to have failed if the status of the Result is down. The
+// status of the component is then determined by the FailureThreshold and
+// SuccessThreshold of the component.
+//
+// Returns the status of the component before and after recording the result.
+func (c *Component) record(result Result, checked time.Time, duration time.Duration) (previous, current Status) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	c.state.result = result
+	c.state.failure = failureReason(result)
+	c.state.lastChecked = checked
+	c.state.duration = duration
+	switch result.Status {
+	case StatusDown:
+		c.state.consecutiveFailures++
+		c.state.consecutiveSuccesses = 0
+	case StatusUp, StatusDegraded:
+		c.state.consecutiveSuccesses++
+		c.state.consecutiveFailures = 0
+		c.state.lastSuccess = checked
+	default:
+		// A check that couldn't determine the status of the component, such
+		// as while a dependency is down, or reported a custom status such as
+		// maintenance, is neither a success nor a failure.
+	}
+	previous = c.state.status
+	c.state.status = c.nextStatus(c.state.status, result.Status)
+	switch {
+	case c.state.status != StatusDown:
+		c.state.downFailures = 0
+	case result.Status == StatusDown:
+		c.state.downFailures++
+	}
+	return previous, c.state.status
+}
+
+// nextStatus determines the status of the component given its current status
+// and the status reported by the most recent check. The caller must hold the
+// state lock.
+func (c *Component) nextStatus(current, reported Status) Status {
+	// Without a previous status there is nothing to retain, so the reported
+	// status is taken as is.
+	// Likewise, an unknown or custom status is taken as is since the thresholds
+	// only apply to checks that succeeded or failed.
+	if current == StatusUnknown || (reported != StatusUp && reported != StatusDegraded && reported != StatusDown) {
+		return reported
+	}
+	if reported == StatusDown {
+		if c.state.consecutiveFailures >= c.FailureThreshold {
+			return StatusDown
+		}
+		if c.DegradeOnFailure && current != StatusDown {
+			return StatusDegraded
+		}
+		return current
+	}
+	// A component that is down must pass enough consecutive checks before it
+	// is considered to have recovered.
+	if current == StatusDown && c.state.consecutiveSuccesses < c.SuccessThreshold {
+		return StatusDown
+	}
+	return reported
+}
+
+// snapshot returns a copy of the current status of the component. It is safe
+// to call concurrently with the monitor goroutine.
+func (c *Component) snapshot() ComponentStatus {
+	cs := ComponentStatus{
+		Name:      c.Name,
+		Critical:  c.severity == SeverityCritical,
+		Severity:  c.severity,
+		DependsOn: slices.Clone(c.DependsOn),
+		Groups:    slices.Clone(c.Groups),
+	}
+	// A component that has not been initialized has no state, which is reported
+	// as the zero value Status.
+	if c.state == nil {
+		return cs
+	}
+	c.state.mu.RLock()
+	cs.Status = c.state.status
+	cs.Message = c.state.result.Message
+	cs.Details = maps.Clone(c.state.result.Details)
+	cs.Latency = c.state.result.Latency
+	if c.state.result.Error != nil {
+		cs.Error = c.state.result.Error.Error()
+	}
+	cs.Failure = c.state.failure
+	cs.LastChecked = c.state.lastChecked
+	cs.LastSuccess = c.state.lastSuccess
+	cs.Duration = c.state.duration
+	cs.ConsecutiveFailures = c.state.consecutiveFailures
+	cs.ConsecutiveSuccesses = c.state.consecutiveSuccesses
+	c.state.mu.RUnlock()
+	return cs
+}
+
+// monitor performs a healthcheck on the component immediately, unless the result
+// of a check has already been recorded, and then at regular intervals, updating
+// the status of the component after each check.
 func (c *Component) monitor(ctx context.Context) {
-	ticker := time.NewTicker(c.Interval)
+	// A check that was started but whose result was discarded, such as when the
+	// component was updated during its first check, doesn't count.
+	c.state.mu.RLock()
+	checked := !c.state.lastChecked.IsZero()
+	c.state.mu.RUnlock()
+	if !checked {
+		c.checkShared(ctx)
+	}
+
+	timer := time.NewTimer(c.nextInterval())
+	defer timer.Stop()
 	for {
 		select {
-		case <-ticker.C:
-			ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
-			err := c.Check(ctx)
-			cancel()
-
-			// If the health check fails, the status of the component is set to
-			// down, otherwise it is set to up.
-			if err != nil {
-				c.status = StatusDown
-			} else {
-				c.status = StatusUp
+		case <-timer.C:
+			c.checkShared(ctx)
+			timer.Reset(c.nextInterval())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh marks a check of the component as in progress unless the result of its
+// last check is younger than maxAge. If a check of the component is already in
+// progress no new check is started, so concurrent callers share the in-progress
+// check.
+//
+// Returns a channel that is closed once the check completes, or nil if the last
+// result is fresh, along with the function starting the check if a new check is
+// required. The check is started separately so that when several components are
+// refreshed together every check is marked as in progress before any starts,
+// allowing a dependent to await the check of its dependency.
+func (c *Component) refresh(parent context.Context, maxAge time.Duration) (<-chan struct{}, func()) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if flight := c.sharedFlight(); flight != nil {
+		return flight, nil
+	}
+	if !c.state.lastChecked.IsZero() && time.Since(c.state.lastChecked) < maxAge {
+		return nil, nil
+	}
+
+	flight := c.beginFlight(parent)
+	if c.inflight != nil {
+		c.inflight.Add(1)
+	}
+	start := func() {
+		go func() {
+			if c.inflight != nil {
+				defer c.inflight.Done()
 			}
+			c.check(parent)
+			c.endFlight(flight)
+		}()
+	}
+	return flight, start
+}
+
+// checkShared checks the component unless a check of the component is already
+// in progress, such as one requested through the HTTP handler, in which case it
+// waits for that check to complete instead so the component is never checked
+// concurrently.
+func (c *Component) checkShared(ctx context.Context) {
+	c.state.mu.Lock()
+	if flight := c.sharedFlight(); flight != nil {
+		c.state.mu.Unlock()
+		select {
+		case <-flight:
 		case <-ctx.Done():
+		}
+		return
+	}
+	flight := c.beginFlight(ctx)
+	c.state.mu.Unlock()
+
+	c.check(ctx)
+	c.endFlight(flight)
+}
+
+// sharedFlight returns the channel of the check of the component in progress,
+// or nil if no check is in progress or its result will be discarded. The caller
+// must hold the state lock.
+func (c *Component) sharedFlight() chan struct{} {
+	if c.state.flight == nil || c.state.flightCtx.Err() != nil {
+		return nil
+	}
+	return c.state.flight
+}
+
+// beginFlight marks a check of the component executed with ctx as in progress.
+// The caller must hold the state lock.
+func (c *Component) beginFlight(ctx context.Context) chan struct{} {
+	flight := make(chan struct{})
+	c.state.flight = flight
+	c.state.flightCtx = ctx
+	return flight
+}
+
+// endFlight marks the check of the component as complete, releasing the callers
+// waiting for it.
+func (c *Component) endFlight(flight chan struct{}) {
+	c.state.mu.Lock()
+	if c.state.flight == flight {
+		c.state.flight = nil
+		c.state.flightCtx = nil
+	}
+	c.state.mu.Unlock()
+	close(flight)
+}
+
+// nextInterval returns the duration to wait before the next health check based
+// on the current state of the component.
+func (c *Component) nextInterval() time.Duration {
+	c.state.mu.RLock()
+	status := c.state.status
+	failures := c.state.downFailures
+	c.state.mu.RUnlock()
+
+	interval := c.Interval
+	if status == StatusDown {
+		if c.UnhealthyInterval > 0 {
+			interval = c.UnhealthyInterval
+		}
+		if c.MaxBackoff > 0 {
+			for i := 1; i < failures && interval < c.MaxBackoff; i++ {
+				interval *= 2
+			}
+			interval = min(interval, c.MaxBackoff)
+		}
+	}
+
+	if c.Jitter > 0 {
+		delta := float64(interval) * c.Jitter
+		interval += time.Duration(delta * (2*rand.Float64() - 1))
+	}
+	return interval
+}
+
+// check performs a single healthcheck of the component and updates its status.
+//
+// The check is executed in its own goroutine so that a panic is recovered and
+// reported as the component being down, and a check that doesn't respect the
+// cancellation of its context is abandoned once the Timeout elapses.
+//
+// The context of the check is derived from the provided context and carries the
+// name of the component and the attempt number. If the provided context is done
+// before the check completes, the check is abandoned and its result discarded so
+// the component retains its last known status.
+func (c *Component) check(parent context.Context) {
+	c.state.mu.Lock()
+	c.state.attempts++
+	attempt := c.state.attempts
+	abandoned := c.state.abandoned
+	c.state.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(withCheckInfo(parent, c.Name, attempt), c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	// While a previously abandoned check is still running the component is not
+	// checked again, and remains down as having timed out. The abandoned check
+	// is given until the Timeout to return, since a check abandoned because its
+	// context was cancelled, such as when the component was updated, typically
+	// returns promptly.
+	if abandoned != nil {
+		select {
+		case <-abandoned:
+			c.state.mu.Lock()
+			if c.state.abandoned == abandoned {
+				c.state.abandoned = nil
+			}
+			c.state.mu.Unlock()
+		case <-ctx.Done():
+			if parent.Err() != nil {
+				return
+			}
+			c.report(Result{
+				Status: StatusDown,
+				Error:  timeoutError(c.Timeout, errCheckStillRunning),
+			}, time.Now(), time.Since(start))
 			return
 		}
 	}
+	if err := c.unavailableDependency(ctx); err != nil {
+		if parent.Err() != nil {
+			return
+		}
+		c.report(Result{Status: StatusUnknown, Message: err.Error(), Error: err}, time.Now(), time.Since(start))
+		return
+	}
+	done := make(chan Result, 1)
+	returned := make(chan struct{})
+	if c.inflight != nil {
+		c.inflight.Add(1)
+	}
+	go func() {
+		if c.inflight != nil {
+			defer c.inflight.Done()
+		}
+		defer close(returned)
+		defer func() {
+			if r := recover(); r != nil {
+				err := &PanicError{Value: r, Stack: debug.Stack()}
+				done <- Result{
+					Status: StatusDown,
+					Error:  err,
+					Details: map[string]any{
+						"panic": fmt.Sprint(r),
+						"stack": string(err.Stack),
+					},
+				}
+			}
+		}()
+		done <- c.probe(ctx)
+	}()
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		// Prefer the result of a check that returned as its context expired,
+		// otherwise the check is abandoned.
+		select {
+		case result = <-done:
+		default:
+			result = Result{
+				Status: StatusDown,
+				Error:  timeoutError(c.Timeout, nil),
+			}
+			c.state.mu.Lock()
+			c.state.abandoned = returned
+			c.state.mu.Unlock()
+		}
+	}
+	if parent.Err() != nil {
+		return
+	}
+	// A check that respected the cancellation of its context but failed because
+	// the Timeout elapsed is still considered to have timed out.
+	if result.Status == StatusDown && errors.Is(ctx.Err(), context.DeadlineExceeded) &&
+		!errors.Is(result.Error, ErrCheckTimeout) {
+		result.Error = timeoutError(c.Timeout, result.Error)
+	}
+	elapsed := time.Since(start)
+
+	if result.Status == "" {
+		result.Status = StatusUp
+	}
+	if result.Latency == 0 {
+		result.Latency = elapsed
+	}
+	c.report(result, start.Add(elapsed), elapsed)
+}
+
+// report records the Result of a check, logs it, and notifies the listener of
+// a change to the status of the component if the status changed.
+//
+// A Result with a status that has not been registered is recorded as down with
+// an error wrapping ErrInvalidStatus, since the status can't be reported.
+func (c *Component) report(result Result, checked time.Time, duration time.Duration) {
+	if !result.Status.Valid() {
+		err := fmt.Errorf("%w %q reported for component %q", ErrInvalidStatus, result.Status, c.Name)
+		if result.Error != nil {
+			err = fmt.Errorf("%w: %w", err, result.Error)
+		}
+		result.Status = StatusDown
+		result.Error = err
+	}
+	previous, current := c.record(result, checked, duration)
+	c.logResult(previous, current, result, duration)
+	if previous != current && c.onChange != nil {
+		c.onChange(c, previous, current, result)
+	}
+}
+
+// timeoutError returns an error wrapping ErrCheckTimeout, and the error returned
+// by the check if any.
+func timeoutError(timeout time.Duration, err error) error {
+	if err == nil {
+		return fmt.Errorf("%w after %s", ErrCheckTimeout, timeout)
+	}
+	return fmt.Errorf("%w after %s: %w", ErrCheckTimeout, timeout, err)
 }
 
 // ComponentStatus represents the status of a component.
 type ComponentStatus struct {
-	Name     string `json:"name"`
-	Critical bool   `json:"critical"`
-	Status   Status `json:"status"`
+	Name     string         `json:"name"`
+	Critical bool           `json:"critical"`
+	Severity Severity       `json:"severity,omitempty"`
+	Status   Status         `json:"status"`
+	Message  string         `json:"message,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+
+	// Names of the components the component depends on.
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Groups the component belongs to.
+	Groups []string `json:"groups,omitempty"`
+
+	// Latency observed while communicating with the component during the most
+	// recent check.
+	Latency time.Duration `json:"-"`
+
+	// Error message of the most recent check if it failed.
+	Error string `json:"error,omitempty"`
+
+	// Classification of why the most recent check failed, if it failed.
+	Failure FailureReason `json:"failure,omitempty"`
+
+	// Time the most recent check completed. The zero value indicates the
+	// component has not been checked yet.
+	LastChecked time.Time `json:"-"`
+
+	// Time the most recent successful check completed. The zero value indicates
+	// the component has never been checked successfully.
+	LastSuccess time.Time `json:"-"`
+
+	// Time taken to execute the most recent check.
+	Duration time.Duration `json:"-"`
+
+	// Number of consecutive checks that have failed, or succeeded, up to and
+	// including the most recent check.
+	ConsecutiveFailures  int `json:"consecutiveFailures"`
+	ConsecutiveSuccesses int `json:"consecutiveSuccesses"`
+}
+
+// componentStatusJSON is the JSON representation of ComponentStatus which
+// represents durations in a human-readable format and omits timestamps that
+// have not been set.
+type componentStatusJSON struct {
+	componentStatusAlias
+	Latency     string     `json:"latency,omitempty"`
+	Duration    string     `json:"duration,omitempty"`
+	LastChecked *time.Time `json:"lastChecked,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+}
+
+// componentStatusAlias prevents infinite recursion when marshalling and
+// unmarshalling ComponentStatus.
+type componentStatusAlias ComponentStatus
+
+// MarshalJSON implements the json.Marshaler interface.
+func (cs ComponentStatus) MarshalJSON() ([]byte, error) {
+	v := componentStatusJSON{
+		componentStatusAlias: componentStatusAlias(cs),
+	}
+	if cs.Latency != 0 {
+		v.Latency = cs.Latency.String()
+	}
+	if cs.Duration != 0 {
+		v.Duration = cs.Duration.String()
+	}
+	if !cs.LastChecked.IsZero() {
+		v.LastChecked = &cs.LastChecked
+	}
+	if !cs.LastSuccess.IsZero() {
+		v.LastSuccess = &cs.LastSuccess
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (cs *ComponentStatus) UnmarshalJSON(data []byte) error {
+	var v componentStatusJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*cs = ComponentStatus(v.componentStatusAlias)
+	if v.Latency != "" {
+		latency, err := time.ParseDuration(v.Latency)
+		if err != nil {
+			return fmt.Errorf("invalid latency: %w", err)
+		}
+		cs.Latency = latency
+	}
+	if v.Duration != "" {
+		duration, err := time.ParseDuration(v.Duration)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
+		}
+		cs.Duration = duration
+	}
+	if v.LastChecked != nil {
+		cs.LastChecked = *v.LastChecked
+	}
+	if v.LastSuccess != nil {
+		cs.LastSuccess = *v.LastSuccess
+	}
+	return nil
 }
 
 // Components is a collection of components that can be checked for health.
@@ -94,85 +791,99 @@ type ComponentStatus struct {
 // or liveness health check endpoint.
 type Components []*Component
 
-// Status returns the overall status of the components.
-func (c Components) Status(ctx context.Context) Status {
-	status := StatusUp
+// Snapshot returns a point-in-time view of the status of every component and
+// the overall status derived from those same component statuses.
+//
+// The overall status is determined by DefaultAggregator.
+func (c Components) Snapshot(ctx context.Context) Snapshot {
+	return c.snapshot(defaultAggregator)
+}
+
+// snapshot returns a Snapshot of the components with the overall status
+// determined by the Aggregator.
+func (c Components) snapshot(aggregator Aggregator) Snapshot {
+	statuses := make([]ComponentStatus, 0, len(c))
 	for _, component := range c {
-		// If the component is critical, and it's down, the overall status is down.
-		if component.status == StatusDown && component.Critical {
-			status = StatusDown
-		}
-		// If the component is not critical, and it's down, the overall status
-		// is degraded is set to degraded unless the overall status has already
-		// been determined to be down.
-		if component.status == StatusDown && !component.Critical && status != StatusDown {
-			status = StatusDegraded
-		}
-		// If the component is degraded regardless of its criticality, the overall
-		// status shall be considered degraded unless the overall status has already
-		// been determined to be down.
-		if component.status == StatusDegraded && status != StatusDown {
-			status = StatusDegraded
-		}
+		statuses = append(statuses, component.snapshot())
+	}
+	return Snapshot{
+		Status:     aggregator.Aggregate(statuses),
+		Timestamp:  time.Now(),
+		Components: statuses,
 	}
-	return status
+}
+
+// Status returns the overall status of the components.
+func (c Components) Status(ctx context.Context) Status {
+	return c.Snapshot(ctx).Status
 }
 
 // ComponentStatus returns the status of each component.
 func (c Components) ComponentStatus(ctx context.Context) []ComponentStatus {
-	statuses := make([]ComponentStatus, 0, len(c))
-	for _, component := range c {
-		statuses = append(statuses, ComponentStatus{
-			Name:     component.Name,
-			Critical: component.Critical,
-			Status:   component.status,
-		})
-	}
-	return statuses
+	return c.Snapshot(ctx).Components
 }
 
+// ServeHTTP is the HTTP handler for the health endpoint which returns the overall
+// status of the components along with the status of each component.
 func (c Components) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serve(w, r, c, handlerConfig{})
+}
 
-	type statusResponse struct {
-		Status     Status            `json:"status"`
-		Uptime     string            `json:"uptime"`
-		Components []ComponentStatus `json:"components"`
-	}
+// Handler returns an http.Handler for the health endpoint configured with the
+// provided options. Without any options the returned handler behaves the same
+// as ServeHTTP.
+func (c Components) Handler(opts ...HandlerOption) http.Handler {
+	return newHandler(c, opts)
+}
 
-	resp := make([]ComponentStatus, 0, len(c))
+// probe returns a snapshot of the components in the requested group, first
+// checking the requested components if a recheck is requested. The checks are
+// not bound to the cancellation of ctx, so a check abandoned by the caller still
+// updates the status of the component.
+func (c Components) probe(ctx context.Context, req probeRequest) Snapshot {
+	if req.recheck {
+		parent := context.WithoutCancel(ctx)
+		flights := make([]<-chan struct{}, 0, len(c))
+		starts := make([]func(), 0, len(c))
+		for _, component := range c {
+			if component.state == nil || component.passive != nil || !req.matches(component) {
+				continue
+			}
+			flight, start := component.refresh(parent, 0)
+			if flight != nil {
+				flights = append(flights, flight)
+			}
+			if start != nil {
+				starts = append(starts, start)
+			}
+		}
+		for _, start := range starts {
+			start()
+		}
+		awaitChecks(ctx, flights)
+	}
+	return c.filter(req.selects).Snapshot(ctx)
+}
 
-	status := StatusUp
+// filter returns the components for which keep returns true.
+func (c Components) filter(keep func(c *Component) bool) Components {
+	comps := make(Components, 0, len(c))
 	for _, component := range c {
-		// If the component is critical, and it's down, the overall status is down.
-		if component.status == StatusDown && component.Critical {
-			status = StatusDown
-		}
-		// If the component is not critical, and it's down, the overall status
-		// is degraded is set to degraded unless the overall status has already
-		// been determined to be down.
-		if component.status == StatusDown && !component.Critical && status != StatusDown {
-			status = StatusDegraded
-		}
-		// If the component is degraded regardless of its criticality, the overall
-		// status shall be considered degraded unless the overall status has already
-		// been determined to be down.
-		if component.status == StatusDegraded && status != StatusDown {
-			status = StatusDegraded
-		}
-
-		resp = append(resp, ComponentStatus{
-			Name:     component.Name,
-			Critical: component.Critical,
-			Status:   component.status,
-		})
-	}
-
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(status.HttpStatusCode())
-
-	_ = json.NewEncoder(w).Encode(statusResponse{
-		Status:     status,
-		Uptime:     time.Since(startTimestamp).String(),
-		Components: resp,
-	})
+		if keep(component) {
+			comps = append(comps, component)
+		}
+	}
+	return comps
+}
+
+// awaitChecks waits for the checks to complete or the context to be done,
+// whichever happens first.
+func awaitChecks(ctx context.Context, flights []<-chan struct{}) {
+	for _, flight := range flights {
+		select {
+		case <-flight:
+		case <-ctx.Done():
+			return
+		}
+	}
 }
@@ -0,0 +1,36 @@
+package health
+
+import (
+	"context"
+)
+
+type contextKey int
+
+const (
+	componentNameKey contextKey = iota
+	attemptKey
+)
+
+// withCheckInfo returns a copy of the context carrying the name of the component
+// being checked and the attempt number of the check.
+func withCheckInfo(ctx context.Context, name string, attempt int) context.Context {
+	ctx = context.WithValue(ctx, componentNameKey, name)
+	return context.WithValue(ctx, attemptKey, attempt)
+}
+
+// ComponentNameFromContext returns the name of the component being checked from
+// the context passed to a CheckFunc or ResultCheckFunc. The boolean is false if
+// the context was not created for a health check.
+func ComponentNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(componentNameKey).(string)
+	return name, ok
+}
+
+// AttemptFromContext returns the attempt number of the health check from the
+// context passed to a CheckFunc or ResultCheckFunc. The attempt number is the
+// number of times the component has been checked, starting at 1. The boolean is
+// false if the context was not created for a health check.
+func AttemptFromContext(ctx context.Context) (int, bool) {
+	attempt, ok := ctx.Value(attemptKey).(int)
+	return attempt, ok
+}
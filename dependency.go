@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrDependencyCycle indicates the dependencies of a component, declared with
+// DependsOn, form a cycle with the dependencies of the registered components.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// ErrHasDependents is returned by Health.Deregister when other registered
+// components depend on the component, since they could never be checked again.
+var ErrHasDependents = errors.New("health: component has dependents")
+
+// DependencyError is the error of a component that was not checked because a
+// component it depends on is down or not registered.
+type DependencyError struct {
+	// Name of the unavailable dependency.
+	Dependency string
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("dependency %q unavailable", e.Dependency)
+}
+
+// unavailableDependency returns a *DependencyError for the first dependency of
+// the component that is down or not registered, or nil if every dependency is
+// available. A check of a dependency in progress is awaited, bounded by the
+// context, so a dependency checked concurrently on demand is evaluated before
+// its dependents.
+func (c *Component) unavailableDependency(ctx context.Context) error {
+	if c.dependency == nil {
+		return nil
+	}
+	for _, name := range c.DependsOn {
+		dep := c.dependency(name)
+		if dep == nil {
+			return &DependencyError{Dependency: name}
+		}
+
+		dep.state.mu.RLock()
+		flight := dep.state.flight
+		dep.state.mu.RUnlock()
+		if flight != nil {
+			select {
+			case <-flight:
+			case <-ctx.Done():
+			}
+		}
+
+		dep.state.mu.RLock()
+		status := dep.state.status
+		dep.state.mu.RUnlock()
+		if status == StatusDown {
+			return &DependencyError{Dependency: name}
+		}
+	}
+	return nil
+}
+
+// lookup returns the registered component with the given name, or nil if no
+// such component is registered.
+func (h *Health) lookup(name string) *Component {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if i := h.indexOf(name); i >= 0 {
+		return h.components[i]
+	}
+	return nil
+}
+
+// dependents returns the names of the registered components which depend on the
+// component with the given name. The caller must hold h.mu.
+func (h *Health) dependents(name string) []string {
+	var names []string
+	for _, component := range h.components {
+		if slices.Contains(component.DependsOn, name) {
+			names = append(names, component.Name)
+		}
+	}
+	return names
+}
+
+// checkDependencies returns a *ValidationError wrapping ErrDependencyCycle if the
+// dependencies of the component form a cycle with the dependencies of the other
+// registered components. The caller must hold h.mu.
+func (h *Health) checkDependencies(component *Component) error {
+	dependsOn := func(name string) []string {
+		if name == component.Name {
+			return component.DependsOn
+		}
+		if i := h.indexOf(name); i >= 0 {
+			return h.components[i].DependsOn
+		}
+		return nil
+	}
+
+	// The registered components never form a cycle, so any cycle must pass
+	// through the component.
+	visited := make(map[string]bool)
+	path := []string{component.Name}
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		for _, dep := range dependsOn(name) {
+			if dep == component.Name {
+				path = append(path, dep)
+				return true
+			}
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			path = append(path, dep)
+			if visit(dep) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	if visit(component.Name) {
+		return &ValidationError{
+			Component: component.Name,
+			Err:       fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(path, " -> ")),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// StatusChange describes a transition of the status of a component, or of the
+// overall status of the application.
+type StatusChange struct {
+	// Name of the component whose status changed. Component is empty if the
+	// overall status of the application changed.
+	Component string
+
+	// Status before the transition.
+	Previous Status
+
+	// Status after the transition.
+	Status Status
+
+	// Error message of the check that caused the transition, if any.
+	Error string
+
+	// Time the transition occurred.
+	Time time.Time
+}
+
+// Overall returns true if the change is of the overall status of the application
+// rather than the status of a single component.
+func (sc StatusChange) Overall() bool {
+	return sc.Component == ""
+}
+
+// OnStatusChange registers a function that is called each time the overall
+// status of the application changes.
+//
+// The function is called from its own goroutine, in the order the changes occur,
+// so a slow function never blocks the monitoring of the components or other
+// listeners.
+func (h *Health) OnStatusChange(fn func(StatusChange)) {
+	h.subscribe(func(sc StatusChange) bool { return sc.Overall() }, fn, nil)
+}
+
+// OnComponentStatusChange registers a function that is called each time the
+// status of any component changes.
+//
+// The function is called from its own goroutine, in the order the changes occur,
+// so a slow function never blocks the monitoring of the components or other
+// listeners.
+func (h *Health) OnComponentStatusChange(fn func(StatusChange)) {
+	h.subscribe(func(sc StatusChange) bool { return !sc.Overall() }, fn, nil)
+}
+
+// Watch returns a channel that receives every change of the status of the
+// components and the overall status of the application, in the order the changes
+// occur. Changes are queued for each watcher, so a slow receiver never blocks the
+// monitoring of the components.
+//
+// The channel is closed when the provided context is done or Health has been
+// shut down and all queued changes have been delivered.
+func (h *Health) Watch(ctx context.Context) <-chan StatusChange {
+	ch := make(chan StatusChange)
+	var s *subscriber
+	s = h.subscribe(nil, func(sc StatusChange) {
+		select {
+		case ch <- sc:
+		case <-ctx.Done():
+		}
+	}, func() {
+		h.unsubscribe(s)
+		close(ch)
+	})
+	go s.run(h.done, ctx.Done())
+	return ch
+}
+
+// subscribe registers a subscriber for status changes. If onStop is nil the
+// subscriber is started immediately, otherwise the caller is responsible for
+// starting it.
+func (h *Health) subscribe(filter func(StatusChange) bool, deliver func(StatusChange), onStop func()) *subscriber {
+	s := &subscriber{
+		signal:  make(chan struct{}, 1),
+		filter:  filter,
+		deliver: deliver,
+		onStop:  onStop,
+	}
+	h.subsMu.Lock()
+	h.subscribers = append(h.subscribers, s)
+	h.subsMu.Unlock()
+
+	if onStop == nil {
+		go s.run(h.done, nil)
+	}
+	return s
+}
+
+func (h *Health) unsubscribe(s *subscriber) {
+	h.subsMu.Lock()
+	defer h.subsMu.Unlock()
+	for i, sub := range h.subscribers {
+		if sub == s {
+			h.subscribers = append(h.subscribers[:i], h.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// componentChanged publishes the status change of a component, and the change
+// of the overall status if the change of the component affected it.
+func (h *Health) componentChanged(c *Component, previous, current Status, result Result) {
+	h.subsMu.Lock()
+	defer h.subsMu.Unlock()
+
+	sc := StatusChange{
+		Component: c.Name,
+		Previous:  previous,
+		Status:    current,
+		Time:      time.Now(),
+	}
+	if result.Error != nil {
+		sc.Error = result.Error.Error()
+	}
+	h.publish(sc)
+	h.evaluateOverallLocked()
+}
+
+// evaluateOverall publishes a change of the overall status if it has changed
+// since it was last evaluated. It is called whenever the set of components or
+// their configuration changes. The caller must not hold h.mu.
+func (h *Health) evaluateOverall() {
+	h.subsMu.Lock()
+	defer h.subsMu.Unlock()
+	h.evaluateOverallLocked()
+}
+
+// evaluateOverallLocked is like evaluateOverall but the caller must hold
+// h.subsMu, which guarantees changes are published in order.
+func (h *Health) evaluateOverallLocked() {
+	// The last known status of the components is used, since evaluating the
+	// overall status must not check the components on demand.
+	status := h.registered().snapshot(h.aggregator).Status
+	if status == h.lastOverall {
+		return
+	}
+	sc := StatusChange{
+		Previous: h.lastOverall,
+		Status:   status,
+		Time:     time.Now(),
+	}
+	h.lastOverall = status
+	h.logger.Info("health: status changed",
+		slog.String("previous_status", string(sc.Previous)),
+		slog.String("status", string(sc.Status)))
+	h.publish(sc)
+}
+
+// publish queues the change for every subscriber. The caller must hold h.subsMu.
+func (h *Health) publish(sc StatusChange) {
+	for _, s := range h.subscribers {
+		s.publish(sc)
+	}
+}
+
+// subscriber receives status changes through an unbounded queue which is
+// drained by its own goroutine, so publishing a change never blocks.
+type subscriber struct {
+	mu      sync.Mutex
+	queue   []StatusChange
+	signal  chan struct{}
+	filter  func(StatusChange) bool
+	deliver func(StatusChange)
+	onStop  func()
+}
+
+func (s *subscriber) publish(sc StatusChange) {
+	if s.filter != nil && !s.filter(sc) {
+		return
+	}
+	s.mu.Lock()
+	s.queue = append(s.queue, sc)
+	s.mu.Unlock()
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscriber) next() (StatusChange, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return StatusChange{}, false
+	}
+	sc := s.queue[0]
+	s.queue = s.queue[1:]
+	return sc, true
+}
+
+// run delivers queued changes until shutdown is done, after which any remaining
+// changes are delivered, or until cancelled.
+func (s *subscriber) run(shutdown, cancelled <-chan struct{}) {
+	if s.onStop != nil {
+		defer s.onStop()
+	}
+	drain := func() {
+		for sc, ok := s.next(); ok; sc, ok = s.next() {
+			s.deliver(sc)
+		}
+	}
+	for {
+		drain()
+		select {
+		case <-s.signal:
+		case <-shutdown:
+			drain()
+			return
+		case <-cancelled:
+			return
+		}
+	}
+}
@@ -36,6 +36,11 @@ func main() {
 		},
 	})
 
+	if err := hc.Start(context.Background()); err != nil {
+		panic(err)
+	}
+	defer hc.Shutdown(context.Background())
+
 	if err := health.EnablePrometheus(hc); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,320 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HandlerOption configures the behavior of an HTTP handler returned by
+// Health.Handler.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	group          string
+	encoder        encoder
+	redactErrors   bool
+	componentPaths bool
+	basePath       string
+	refresh        bool
+	statusCodes    map[Status]int
+	retryAfter     time.Duration
+}
+
+// WithStatusCode overrides the HTTP status code returned by the handler while
+// the status is the given Status, such as returning 207 Multi-Status while the
+// status is degraded for load balancers that need to distinguish it. Without an
+// override the status code of the Status is used, see Status.HttpStatusCode.
+//
+// Panics if the code is not a valid HTTP status code between 100 and 599.
+func WithStatusCode(status Status, code int) HandlerOption {
+	if code < 100 || code > 599 {
+		panic(fmt.Sprintf("health: invalid http status code %d for status %q", code, status))
+	}
+	return func(conf *handlerConfig) {
+		if conf.statusCodes == nil {
+			conf.statusCodes = make(map[Status]int)
+		}
+		conf.statusCodes[status] = code
+	}
+}
+
+// WithRetryAfter sets the Retry-After header of responses with a 429 Too Many
+// Requests or 503 Service Unavailable status code, indicating to clients how
+// long to wait before probing the health endpoint again. The duration is
+// rounded up to whole seconds.
+func WithRetryAfter(d time.Duration) HandlerOption {
+	return func(conf *handlerConfig) {
+		conf.retryAfter = d
+	}
+}
+
+// encoder writes the response of the health endpoint in a particular format.
+type encoder interface {
+	// encodeSnapshot writes the overall status and the status of each
+	// component.
+	encodeSnapshot(w http.ResponseWriter, r *http.Request, snapshot Snapshot, conf handlerConfig)
+
+	// encodeComponent writes the status of a single component.
+	encodeComponent(w http.ResponseWriter, r *http.Request, status ComponentStatus, conf handlerConfig)
+}
+
+// encoding returns the encoder of the responses, which is JSON by default.
+func (conf handlerConfig) encoding() encoder {
+	if conf.encoder == nil {
+		return jsonEncoder{}
+	}
+	return conf.encoder
+}
+
+// statusCode returns the HTTP status code of the response while the status is
+// the given Status.
+func (conf handlerConfig) statusCode(status Status) int {
+	if code, ok := conf.statusCodes[status]; ok {
+		return code
+	}
+	return status.HttpStatusCode()
+}
+
+// writeHeader writes the headers and status code of the response for the given
+// Status. Returns false if the body of the response must not be written, such as
+// in response to a HEAD request.
+func (conf handlerConfig) writeHeader(w http.ResponseWriter, r *http.Request, status Status, contentType string) bool {
+	code := conf.statusCode(status)
+
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	// Health responses must always reflect the current status, never a cached
+	// response from an intermediary.
+	h.Set("Cache-Control", "no-store")
+	if conf.retryAfter > 0 && (code == http.StatusServiceUnavailable || code == http.StatusTooManyRequests) {
+		seconds := int64((conf.retryAfter + time.Second - 1) / time.Second)
+		h.Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	w.WriteHeader(code)
+	return r.Method != http.MethodHead
+}
+
+// WithRedactedErrors omits the error messages of failed checks from the response,
+// along with the panic message and stack trace of checks that panicked. This is
+// useful when the health endpoint is publicly accessible and the error messages
+// may leak details about the internals of the application, such as hostnames or
+// credentials embedded in connection strings.
+func WithRedactedErrors() HandlerOption {
+	return func(conf *handlerConfig) {
+		conf.redactErrors = true
+	}
+}
+
+// redact returns the status of the component with the error message, and the
+// panic details of a check that panicked, removed if errors are redacted.
+func (conf handlerConfig) redact(status ComponentStatus) ComponentStatus {
+	if !conf.redactErrors {
+		return status
+	}
+	status.Error = ""
+	if status.Failure == FailurePanic && status.Details != nil {
+		status.Details = maps.Clone(status.Details)
+		delete(status.Details, "panic")
+		delete(status.Details, "stack")
+		if len(status.Details) == 0 {
+			status.Details = nil
+		}
+	}
+	return status
+}
+
+// WithRefresh allows clients to check the components before the handler responds,
+// by adding the query parameter refresh=true to the request or, combined with
+// the WithComponentRoutes option, by sending a POST request to the path of a
+// component. Since this lets clients trigger checks of the dependencies of the
+// application, it should only be enabled on endpoints that aren't publicly
+// accessible. Without this option the refresh query parameter is ignored.
+func WithRefresh() HandlerOption {
+	return func(conf *handlerConfig) {
+		conf.refresh = true
+	}
+}
+
+// WithComponentRoutes serves the status of individual components beneath the
+// base path the handler is mounted at. A GET request to basePath returns the
+// overall status, a GET request to basePath/{component} returns the detailed
+// status of a single component, and, if the WithRefresh option is enabled, a
+// POST request to basePath/{component} checks the component immediately before
+// returning its status. A GET request to basePath/{group}, where no component
+// has the same name as the group, returns the status of the components in the
+// group, such as readiness. A 404 Not Found status code is returned if no
+// component or group with the name exists.
+//
+// The base path is matched against the path of the request as received by the
+// handler, so if the handler is wrapped with http.StripPrefix the base path
+// should be empty.
+func WithComponentRoutes(basePath string) HandlerOption {
+	return func(conf *handlerConfig) {
+		conf.componentPaths = true
+		conf.basePath = strings.TrimSuffix(basePath, "/")
+	}
+}
+
+// prober is implemented by the sources of health status served by the health
+// endpoint, Health and Components.
+type prober interface {
+	// probe returns a snapshot of the components in the requested group, first
+	// checking the requested components if a recheck is requested.
+	probe(ctx context.Context, req probeRequest) Snapshot
+}
+
+// probeRequest describes the components requested from the health endpoint.
+type probeRequest struct {
+	// Group of the components, or empty for every component.
+	group string
+
+	// Name of a single component, or empty for every component in the group.
+	component string
+
+	// Names of the components to ignore.
+	exclude []string
+
+	// Whether the components should be checked before responding.
+	recheck bool
+}
+
+// selects returns true if the component belongs to the requested group and is
+// not excluded.
+func (req probeRequest) selects(c *Component) bool {
+	if req.group != "" && !c.inGroup(req.group) {
+		return false
+	}
+	return !slices.Contains(req.exclude, c.Name)
+}
+
+// matches returns true if the component is one of the requested components.
+func (req probeRequest) matches(c *Component) bool {
+	return req.selects(c) && (req.component == "" || c.Name == req.component)
+}
+
+// newHandler returns an http.Handler serving the health endpoint for the source
+// configured with the provided options.
+func newHandler(source prober, opts []HandlerOption) http.Handler {
+	conf := handlerConfig{}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, source, conf)
+	})
+}
+
+// serve handles a request to the health endpoint. If refreshing is enabled and the
+// query parameter refresh is true the components are checked before responding,
+// and components named by the query parameter exclude are ignored.
+func serve(w http.ResponseWriter, r *http.Request, source prober, conf handlerConfig) {
+	query := r.URL.Query()
+	var refresh bool
+	if conf.refresh {
+		refresh, _ = strconv.ParseBool(query.Get("refresh"))
+	}
+	exclude := query["exclude"]
+
+	name, ok := conf.component(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if name == "" {
+		req := probeRequest{group: conf.group, exclude: exclude, recheck: refresh}
+		conf.encoding().encodeSnapshot(w, r, source.probe(r.Context(), req), conf)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+	case r.Method == http.MethodPost && conf.refresh:
+		refresh = true
+	default:
+		allow := "GET, HEAD"
+		if conf.refresh {
+			allow += ", POST"
+		}
+		w.Header().Set("Allow", allow)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	req := probeRequest{group: conf.group, component: name, exclude: exclude, recheck: refresh}
+	status, found := source.probe(r.Context(), req).Component(name)
+	if found {
+		conf.encoding().encodeComponent(w, r, status, conf)
+		return
+	}
+
+	// A name which isn't a component may be a group, such as readiness,
+	// unless the handler is already restricted to a group.
+	if conf.group == "" && r.Method != http.MethodPost {
+		req = probeRequest{group: name, exclude: exclude, recheck: refresh}
+		if snapshot := source.probe(r.Context(), req); len(snapshot.Components) > 0 {
+			conf.group = name
+			conf.encoding().encodeSnapshot(w, r, snapshot, conf)
+			return
+		}
+	}
+	http.Error(w, ErrComponentNotFound.Error(), http.StatusNotFound)
+}
+
+// component returns the name of the component addressed by the request path, or
+// an empty string if the path addresses the overall status. Returns false if the
+// path is not beneath the base path.
+func (conf handlerConfig) component(path string) (string, bool) {
+	if !conf.componentPaths {
+		return "", true
+	}
+	rest, ok := strings.CutPrefix(path, conf.basePath)
+	if !ok {
+		return "", false
+	}
+	if rest != "" && rest[0] != '/' {
+		return "", false
+	}
+	return strings.TrimPrefix(rest, "/"), true
+}
+
+// jsonEncoder writes the responses of the health endpoint as JSON.
+type jsonEncoder struct{}
+
+// encodeComponent writes the status of a single component as the JSON response
+// of the health endpoint.
+func (jsonEncoder) encodeComponent(w http.ResponseWriter, r *http.Request, status ComponentStatus, conf handlerConfig) {
+	status = conf.redact(status)
+	if !conf.writeHeader(w, r, status.Status, "application/json;charset=utf-8") {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// encodeSnapshot writes the Snapshot as the JSON response of the health endpoint.
+func (jsonEncoder) encodeSnapshot(w http.ResponseWriter, r *http.Request, snapshot Snapshot, conf handlerConfig) {
+
+	type statusResponse struct {
+		Status     Status            `json:"status"`
+		Uptime     string            `json:"uptime"`
+		Components []ComponentStatus `json:"components"`
+	}
+
+	for i := range snapshot.Components {
+		snapshot.Components[i] = conf.redact(snapshot.Components[i])
+	}
+
+	if !conf.writeHeader(w, r, snapshot.Status, "application/json;charset=utf-8") {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(statusResponse{
+		Status:     snapshot.Status,
+		Uptime:     time.Since(startTimestamp).String(),
+		Components: snapshot.Components,
+	})
+}
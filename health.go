@@ -2,7 +2,14 @@ package health
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,51 +19,496 @@ func init() {
 	startTimestamp = time.Now()
 }
 
+// ErrAlreadyStarted is returned by Health.Start when the Health instance has
+// already been started.
+var ErrAlreadyStarted = errors.New("health: already started")
+
+// ErrComponentNotFound is returned when attempting to modify a component that
+// is not registered.
+var ErrComponentNotFound = errors.New("health: component not found")
+
+// ErrShutdown is returned by Health.Start when the Health instance has already
+// been shut down. A Health instance cannot be restarted once shut down.
+var ErrShutdown = errors.New("health: shutdown")
+
 // Health monitors the health of services/components that an application depends
 // on and tracks their status to determine the overall health of the application.
 //
+// Components are not checked until Start is called. Components registered before
+// Start begin being monitored when Start is called, and components registered
+// after Start begin being monitored immediately. Shutdown stops monitoring.
+//
 // Health implements the http.Handler interface and can be used to expose the
 // health status of the application via an HTTP endpoint.
 type Health struct {
+	mu         sync.RWMutex
 	components Components
+	started    bool
+	stopped    bool
 	ctx        context.Context
 	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	done       chan struct{}
+
+	logger               *slog.Logger
+	repeatedFailureLevel slog.Level
+	aggregator           Aggregator
+
+	onDemand        bool
+	onDemandTimeout time.Duration
+
+	// startupComplete is set once the startup group has been up.
+	startupComplete atomic.Bool
+
+	// notStartedWarning ensures serving the health endpoint before Start is
+	// only logged once.
+	notStartedWarning sync.Once
+
+	subsMu      sync.Mutex
+	subscribers []*subscriber
+	lastOverall Status
 }
 
 // New initializes a new Health instance with the provided components.
 //
 // Additional components can be registered by calling the Register method on the
-// Health instance.
+// Health instance. The components are not monitored until Start is called.
+//
+// Panics if any of the components are invalid, see TryRegister.
 func New(components ...Component) *Health {
-	comps := make([]*Component, 0)
-	for _, c := range components {
-		comp := c
-		comps = append(comps, &comp)
+	return NewWithOptions(WithComponents(components...))
+}
+
+// NewWithOptions initializes a new Health instance configured with the provided
+// options. Components can be provided using the WithComponents option or by
+// calling the Register method on the Health instance.
+func NewWithOptions(opts ...Option) *Health {
+	h := &Health{
+		components:           make(Components, 0),
+		done:                 make(chan struct{}),
+		logger:               discardLogger,
+		repeatedFailureLevel: slog.LevelDebug,
+		aggregator:           defaultAggregator,
+		lastOverall:          StatusUnknown,
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	return &Health{
-		components: comps,
-		ctx:        ctx,
-		cancel:     cancel,
+	return h
+}
+
+// Start begins monitoring all the registered components. Monitoring continues
+// until Shutdown is called or the provided context is cancelled.
+//
+// Start returns ErrAlreadyStarted if the Health instance has already been
+// started, or ErrShutdown if it has already been shut down.
+func (h *Health) Start(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.stopped {
+		return ErrShutdown
 	}
+	if h.started {
+		return ErrAlreadyStarted
+	}
+	h.ctx, h.cancel = context.WithCancel(ctx)
+	h.started = true
+	for _, component := range h.components {
+		h.monitor(component)
+	}
+	return nil
 }
 
 // Register adds a component to be monitored and considered in the overall health
-// of the application.
+// of the application. If the Health instance has already been started the
+// component is monitored immediately, otherwise monitoring begins when Start is
+// called. Components registered after Shutdown are reported with their initial
+// status but are never checked.
 //
-// Panics if the component does not have a non-nil check function.
+// Panics if the component is invalid, see TryRegister.
 func (h *Health) Register(component Component) {
-	if component.Check == nil {
-		panic("health: component must have a non-nil check")
+	if err := h.TryRegister(component); err != nil {
+		panic(err)
+	}
+}
+
+// TryRegister is like Register but returns an error rather than panicking if
+// the component is invalid. The returned error is a *ValidationError wrapping
+// ErrEmptyName if the component doesn't have a name, ErrDuplicateName if a
+// component with the same name is already registered, ErrNilCheck if the
+// component doesn't have a check, ErrInvalidTimeout if the Timeout is not less
+// than the Interval, ErrDependencyCycle if the dependencies of the component form
+// a cycle, or ErrInvalidConfig for any other invalid configuration.
+func (h *Health) TryRegister(component Component) error {
+	if err := component.init(); err != nil {
+		return err
+	}
+	return h.add(&component)
+}
+
+// add registers the initialized component, monitoring it if the Health instance
+// is running.
+func (h *Health) add(component *Component) error {
+	defer h.evaluateOverall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.indexOf(component.Name) >= 0 {
+		return &ValidationError{Component: component.Name, Err: ErrDuplicateName}
+	}
+	if err := h.checkDependencies(component); err != nil {
+		return err
+	}
+	h.attach(component)
+	h.components = append(h.components, component)
+	if h.started && !h.stopped {
+		h.monitor(component)
+	}
+	h.logComponent("health: component registered", component)
+	return nil
+}
+
+// monitor starts a goroutine monitoring the component which, along with the
+// checks it executes, is tracked so Shutdown can wait for it to exit. The caller
+// must hold h.mu.
+func (h *Health) monitor(component *Component) {
+	// Passive components are never checked, their status is set by the
+	// application.
+	if component.passive != nil {
+		return
+	}
+
+	// Checks of the component, including checks requested on demand, are
+	// cancelled once the component stops being monitored.
+	component.ctx, component.stop = context.WithCancel(h.ctx)
+
+	// Components checked on demand are checked when their status is requested
+	// rather than in the background.
+	if h.onDemand {
+		return
+	}
+
+	ctx := component.ctx
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		component.monitor(ctx)
+	}()
+}
+
+// attach configures the component to log its results and publish changes to its
+// status using the configuration of the Health instance.
+func (h *Health) attach(component *Component) {
+	component.logger = h.logger
+	component.repeatedFailureLevel = h.repeatedFailureLevel
+	component.onChange = h.componentChanged
+	component.inflight = &h.wg
+	component.dependency = h.lookup
+}
+
+// Deregister stops monitoring the component with the given name and removes it
+// from the overall health of the application. Any in-flight check of the
+// component is cancelled and its result discarded.
+//
+// Returns ErrComponentNotFound if no component with the given name is registered,
+// or an error wrapping ErrHasDependents if other registered components depend on
+// the component, in which case the dependents must be deregistered first.
+func (h *Health) Deregister(name string) error {
+	defer h.evaluateOverall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := h.indexOf(name)
+	if i < 0 {
+		return ErrComponentNotFound
+	}
+	if dependents := h.dependents(name); len(dependents) > 0 {
+		return fmt.Errorf("%w: %q is depended on by %s", ErrHasDependents, name, strings.Join(dependents, ", "))
+	}
+	if stop := h.components[i].stop; stop != nil {
+		stop()
+	}
+	h.components = slices.Delete(h.components, i, i+1)
+	h.logger.Info("health: component deregistered", slog.String("component", name))
+	return nil
+}
+
+// Replace replaces the registered component having the same name as the provided
+// component, such as when the connection pool a check depends on is recreated.
+// The replaced component stops being monitored and the new component starts with
+// a fresh status, being monitored immediately if the Health instance has been
+// started.
+//
+// Returns ErrComponentNotFound if no component with the same name is registered,
+// or a *ValidationError if the component is invalid.
+func (h *Health) Replace(component Component) error {
+	if err := component.init(); err != nil {
+		return err
+	}
+	defer h.evaluateOverall()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.swap(&component); err != nil {
+		return err
+	}
+	h.logComponent("health: component replaced", &component)
+	return nil
+}
+
+// Update updates the configuration of the component with the given name while
+// retaining its current status. The update function is called with a copy of
+// the component which it may modify, typically the Interval, Timeout, or
+// Critical fields. The changes take effect immediately, with the next check of
+// the component being scheduled using the updated configuration. An in-flight
+// check of the component is cancelled and its result discarded. If only one of
+// the Critical and Severity fields is changed the other is derived from it, so
+// a component made not critical has the severity SeverityMajor.
+//
+// The update function is called without any locks held, so it may call methods
+// of the Health instance. If the component is replaced or updated concurrently,
+// the update function is called again with a copy of the latest component.
+//
+// Returns ErrComponentNotFound if no component with the given name is registered,
+// or a *ValidationError if the updated component is invalid. The name of a
+// component cannot be changed, use Deregister and Register instead.
+func (h *Health) Update(name string, update func(c *Component)) error {
+	defer h.evaluateOverall()
+	for {
+		h.mu.RLock()
+		i := h.indexOf(name)
+		if i < 0 {
+			h.mu.RUnlock()
+			return ErrComponentNotFound
+		}
+		existing := h.components[i]
+		component := *existing
+		h.mu.RUnlock()
+
+		component.ctx, component.stop = nil, nil
+		update(&component)
+		if component.Name != name {
+			return fmt.Errorf("health: cannot rename component %q to %q", name, component.Name)
+		}
+		// A Timeout that was defaulted is defaulted again from the updated
+		// Interval unless the Timeout was changed.
+		if existing.defaultTimeout && component.Timeout == existing.Timeout {
+			component.Timeout = 0
+		}
+		// If only one of Critical and Severity was changed the other is derived
+		// from it, so lowering the severity of a critical component, or making a
+		// component not critical, takes effect rather than conflicting.
+		switch {
+		case component.Critical != existing.Critical && component.Severity == existing.Severity:
+			component.Severity = ""
+		case component.Severity != existing.Severity && component.Critical == existing.Critical:
+			component.Critical = false
+		}
+		if err := component.init(); err != nil {
+			return err
+		}
+		component.state = existing.state
+
+		updated, err := h.swapIf(existing, &component)
+		if err != nil {
+			return err
+		}
+		if updated {
+			return nil
+		}
+	}
+}
+
+// swapIf replaces the existing component with the updated component unless the
+// existing component has been replaced or deregistered since it was read, in
+// which case false is returned.
+func (h *Health) swapIf(existing, component *Component) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := h.indexOf(component.Name)
+	if i < 0 {
+		return false, ErrComponentNotFound
+	}
+	if h.components[i] != existing {
+		return false, nil
+	}
+	if err := h.swap(component); err != nil {
+		return false, err
+	}
+	h.logComponent("health: component updated", component)
+	return true, nil
+}
+
+// swap replaces the registered component with the same name, stopping the
+// monitoring of the existing component and monitoring the new component if the
+// Health instance is running. The caller must hold h.mu.
+func (h *Health) swap(component *Component) error {
+	i := h.indexOf(component.Name)
+	if i < 0 {
+		return ErrComponentNotFound
+	}
+	if err := h.checkDependencies(component); err != nil {
+		return err
+	}
+	if stop := h.components[i].stop; stop != nil {
+		stop()
 	}
-	component.init()
-	go component.monitor(h.ctx)
-	h.components = append(h.components, &component)
+	h.attach(component)
+	h.components[i] = component
+	if h.started && !h.stopped {
+		h.monitor(component)
+	}
+	return nil
+}
+
+// logComponent logs the message along with the configuration of the component.
+func (h *Health) logComponent(msg string, component *Component) {
+	if component.passive != nil {
+		h.logger.Info(msg,
+			slog.String("component", component.Name),
+			slog.Bool("critical", component.severity == SeverityCritical),
+			slog.String("severity", string(component.severity)),
+			slog.Bool("passive", true),
+			slog.Duration("ttl", component.passive.ttl))
+		return
+	}
+	h.logger.Info(msg,
+		slog.String("component", component.Name),
+		slog.Bool("critical", component.severity == SeverityCritical),
+		slog.String("severity", string(component.severity)),
+		slog.Duration("interval", component.Interval),
+		slog.Duration("timeout", component.Timeout))
+}
+
+// indexOf returns the index of the component with the given name, or -1 if
+// no such component is registered. The caller must hold h.mu.
+func (h *Health) indexOf(name string) int {
+	return slices.IndexFunc(h.components, func(c *Component) bool {
+		return c.Name == name
+	})
+}
+
+// Snapshot returns an immutable point-in-time view of the overall status of the
+// application and the status of each component. Snapshot is safe to call
+// concurrently while components are being checked and registered.
+//
+// If the Health instance checks components on demand, see WithOnDemandChecks,
+// the components are checked before the snapshot is taken.
+func (h *Health) Snapshot(ctx context.Context) Snapshot {
+	h.refresh(ctx, probeRequest{}, false)
+	return h.registered().snapshot(h.aggregator)
 }
 
 // Status returns the overall status of the application.
 func (h *Health) Status(ctx context.Context) Status {
-	return h.components.Status(ctx)
+	return h.Snapshot(ctx).Status
+}
+
+// refresh checks the registered components matching the request in parallel if
+// the Health instance is running. Unless force is true, components are only
+// checked if the Health instance checks components on demand and the cached
+// result of the component is stale.
+//
+// refresh returns once the checks complete, the on-demand timeout elapses, or
+// the provided context is done, whichever happens first. The checks themselves
+// are bounded by the Timeout of each component rather than the provided context,
+// so a check abandoned by one caller still updates the status of the component
+// for the next.
+func (h *Health) refresh(ctx context.Context, req probeRequest, force bool) {
+	h.mu.RLock()
+	if !h.started || h.stopped || (!h.onDemand && !force) {
+		h.mu.RUnlock()
+		return
+	}
+	flights := make([]<-chan struct{}, 0, len(h.components))
+	starts := make([]func(), 0, len(h.components))
+	for _, component := range h.components {
+		if component.passive != nil || !req.matches(component) {
+			continue
+		}
+		maxAge := component.CacheTTL
+		if force {
+			maxAge = 0
+		}
+		flight, start := component.refresh(component.ctx, maxAge)
+		if flight != nil {
+			flights = append(flights, flight)
+		}
+		if start != nil {
+			starts = append(starts, start)
+		}
+	}
+	h.mu.RUnlock()
+
+	// Checks are only started once every check is marked as in progress, so a
+	// dependent checked before its dependency awaits the check of the dependency
+	// rather than reading its previous status.
+	for _, start := range starts {
+		start()
+	}
+
+	if h.onDemandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.onDemandTimeout)
+		defer cancel()
+	}
+	awaitChecks(ctx, flights)
+}
+
+// probe returns a snapshot of the components in the requested group, first
+// checking the requested components if a recheck is requested or the Health
+// instance checks components on demand.
+//
+// Following the semantics of Kubernetes probes, the readiness group is reported
+// as down once Shutdown has been called so traffic is drained from the instance,
+// and the startup group is reported as up once it has been up, regardless of
+// the status of its components afterward.
+func (h *Health) probe(ctx context.Context, req probeRequest) Snapshot {
+	h.mu.RLock()
+	started := h.started
+	h.mu.RUnlock()
+	if !started {
+		// Components are not checked until Start is called, so they would be
+		// reported as unknown indefinitely.
+		h.notStartedWarning.Do(func() {
+			h.logger.Warn("health: serving health endpoint before Start was called, components are not being checked")
+		})
+	}
+
+	h.refresh(ctx, req, req.recheck)
+	snapshot := h.registered().filter(req.selects).snapshot(h.aggregator)
+
+	switch req.group {
+	case GroupReadiness:
+		h.mu.RLock()
+		stopped := h.stopped
+		h.mu.RUnlock()
+		if stopped {
+			snapshot.Status = StatusDown
+		}
+	case GroupStartup:
+		// Startup is only complete once every component of the startup group
+		// has been up, not just those that weren't excluded.
+		if snapshot.Status == StatusUp && len(req.exclude) == 0 {
+			h.startupComplete.Store(true)
+		}
+		if h.startupComplete.Load() {
+			snapshot.Status = StatusUp
+		}
+	}
+	return snapshot
+}
+
+// registered returns a copy of the registered components.
+func (h *Health) registered() Components {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	comps := make(Components, len(h.components))
+	copy(comps, h.components)
+	return comps
 }
 
 // ServeHTTP is the HTTP handler for the health endpoint which returns the overall
@@ -65,7 +517,14 @@ func (h *Health) Status(ctx context.Context) Status {
 // If the application overall status is Down a 503 Service Unavailable status code
 // is returned.
 func (h *Health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h.components.ServeHTTP(w, r)
+	serve(w, r, h, handlerConfig{})
+}
+
+// Handler returns an http.Handler for the health endpoint configured with the
+// provided options. Without any options the returned handler behaves the same
+// as ServeHTTP.
+func (h *Health) Handler(opts ...HandlerOption) http.Handler {
+	return newHandler(h, opts)
 }
 
 // HandlerFunc returns an http.HandlerFunc for the health endpoint which returns
@@ -79,8 +538,43 @@ func (h *Health) HandlerFunc() http.HandlerFunc {
 	}
 }
 
-// Shutdown stops monitoring the health of the components but will continue to
+// Shutdown stops monitoring the health of the components, cancelling the context
+// of any in-flight checks, and waits for the in-flight checks to return. The
+// results of the cancelled checks are discarded, so Health will continue to
 // return the last known status of the components.
-func (h *Health) Shutdown() {
-	h.cancel()
+//
+// If the provided context is done before all in-flight checks return, Shutdown
+// returns the context's error. Shutdown can be called again to continue waiting,
+// or Done can be used to observe when shutdown completes. Calling Shutdown more
+// than once, or on a Health instance that was never started, is safe.
+func (h *Health) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	if !h.stopped {
+		h.stopped = true
+		if h.cancel != nil {
+			h.cancel()
+		}
+		h.logger.Info("health: shutting down")
+		go func() {
+			h.wg.Wait()
+			h.logger.Info("health: shutdown complete")
+			close(h.done)
+		}()
+	}
+	h.mu.Unlock()
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		h.logger.Warn("health: shutdown did not complete before context was done",
+			slog.String("error", ctx.Err().Error()))
+		return ctx.Err()
+	}
+}
+
+// Done returns a channel that is closed once Shutdown has been called and all
+// monitoring of the components, including in-flight checks, has stopped.
+func (h *Health) Done() <-chan struct{} {
+	return h.done
 }
@@ -4,9 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -197,9 +205,9 @@ func TestHealth_Status(t *testing.T) {
 			for i := 0; i < len(h.components); i++ {
 				err := h.components[i].Check(context.Background())
 				if err == nil {
-					h.components[i].status = StatusUp
+					h.components[i].setStatus(StatusUp)
 				} else {
-					h.components[i].status = StatusDown
+					h.components[i].setStatus(StatusDown)
 				}
 			}
 			actual := h.Status(context.Background())
@@ -248,11 +256,13 @@ func TestHealth_ServeHTTP(t *testing.T) {
 					{
 						Name:     "redis",
 						Critical: false,
+						Severity: SeverityMajor,
 						Status:   StatusUp,
 					},
 					{
 						Name:     "mongo",
 						Critical: true,
+						Severity: SeverityCritical,
 						Status:   StatusUp,
 					},
 				},
@@ -285,11 +295,13 @@ func TestHealth_ServeHTTP(t *testing.T) {
 					{
 						Name:     "redis",
 						Critical: false,
+						Severity: SeverityMajor,
 						Status:   StatusUp,
 					},
 					{
 						Name:     "mongo",
 						Critical: true,
+						Severity: SeverityCritical,
 						Status:   StatusDown,
 					},
 				},
@@ -322,11 +334,13 @@ func TestHealth_ServeHTTP(t *testing.T) {
 					{
 						Name:     "redis",
 						Critical: false,
+						Severity: SeverityMajor,
 						Status:   StatusDown,
 					},
 					{
 						Name:     "mongo",
 						Critical: true,
+						Severity: SeverityCritical,
 						Status:   StatusUp,
 					},
 				},
@@ -359,11 +373,13 @@ func TestHealth_ServeHTTP(t *testing.T) {
 					{
 						Name:     "redis",
 						Critical: false,
+						Severity: SeverityMajor,
 						Status:   StatusDown,
 					},
 					{
 						Name:     "mongo",
 						Critical: true,
+						Severity: SeverityCritical,
 						Status:   StatusDown,
 					},
 				},
@@ -377,9 +393,9 @@ func TestHealth_ServeHTTP(t *testing.T) {
 			for i := 0; i < len(h.components); i++ {
 				err := h.components[i].Check(context.Background())
 				if err == nil {
-					h.components[i].status = StatusUp
+					h.components[i].setStatus(StatusUp)
 				} else {
-					h.components[i].status = StatusDown
+					h.components[i].setStatus(StatusDown)
 				}
 			}
 			w := httptest.NewRecorder()
@@ -395,3 +411,2558 @@ func TestHealth_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestHealth_Snapshot(t *testing.T) {
+	h := New(
+		Component{
+			Name:     "redis",
+			Critical: false,
+			Check: func(ctx context.Context) error {
+				return nil
+			},
+		},
+		Component{
+			Name:     "mongo",
+			Critical: true,
+			Check: func(ctx context.Context) error {
+				return nil
+			},
+		},
+	)
+
+	// Concurrently mutate the component statuses and register components while
+	// taking snapshots. Run with -race to verify the state is properly guarded.
+	mongo := h.components[1]
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if i%2 == 0 {
+				mongo.setStatus(StatusDown)
+			} else {
+				mongo.setStatus(StatusUp)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			h.Register(Component{
+				Name: fmt.Sprintf("http-%d", i),
+				Check: func(ctx context.Context) error {
+					return nil
+				},
+			})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		snapshot := h.Snapshot(context.Background())
+		mongo, ok := snapshot.Component("mongo")
+		assert.True(t, ok)
+		// The overall status must always agree with the component statuses
+		// contained in the same snapshot.
+		if mongo.Status == StatusDown {
+			assert.Equal(t, StatusDown, snapshot.Status)
+		} else {
+			assert.NotEqual(t, StatusDown, snapshot.Status)
+		}
+	}
+	wg.Wait()
+
+	snapshot := h.Snapshot(context.Background())
+	assert.Len(t, snapshot.Components, 12)
+	_, ok := snapshot.Component("postgres")
+	assert.False(t, ok)
+}
+
+func TestHealth_Lifecycle(t *testing.T) {
+	counter := func(n *atomic.Int32) CheckFunc {
+		return func(ctx context.Context) error {
+			n.Add(1)
+			return nil
+		}
+	}
+
+	var constructed, beforeStart, afterStart atomic.Int32
+	h := New(Component{
+		Name:     "constructed",
+		Timeout:  5 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+		Check:    counter(&constructed),
+	})
+	h.Register(Component{
+		Name:     "before-start",
+		Timeout:  5 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+		Check:    counter(&beforeStart),
+	})
+
+	// Components must not be checked until Start is called.
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(0), constructed.Load())
+	assert.Equal(t, int32(0), beforeStart.Load())
+
+	assert.NoError(t, h.Start(context.Background()))
+	assert.ErrorIs(t, h.Start(context.Background()), ErrAlreadyStarted)
+
+	h.Register(Component{
+		Name:     "after-start",
+		Timeout:  5 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+		Check:    counter(&afterStart),
+	})
+
+	assert.Eventually(t, func() bool {
+		return constructed.Load() > 0 && beforeStart.Load() > 0 && afterStart.Load() > 0
+	}, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, h.Shutdown(context.Background()))
+	assert.ErrorIs(t, h.Start(context.Background()), ErrShutdown)
+
+	// No further checks are performed once Shutdown has returned.
+	calls := constructed.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, calls, constructed.Load())
+}
+
+func TestHealth_ServeBeforeStart(t *testing.T) {
+	handler := &recordingHandler{}
+	h := NewWithOptions(
+		WithLogger(slog.New(handler)),
+		WithComponents(Component{
+			Name:     "database",
+			Critical: true,
+			Check: func(ctx context.Context) error {
+				return nil
+			},
+		}),
+	)
+
+	// Serving the endpoint before Start reports the components as unknown, which
+	// is logged once as a warning.
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	}
+
+	var warnings int
+	for _, record := range handler.messages() {
+		if record["level"] == slog.LevelWarn {
+			warnings++
+			assert.Contains(t, record["msg"], "before Start")
+		}
+	}
+	assert.Equal(t, 1, warnings)
+}
+
+func TestHealth_Shutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	h := New(Component{
+		Name:     "slow",
+		Timeout:  time.Second,
+		Interval: 2 * time.Second,
+		Check: func(ctx context.Context) error {
+			once.Do(func() { close(started) })
+			<-release
+			return nil
+		},
+	})
+	assert.NoError(t, h.Start(context.Background()))
+	<-started
+
+	// Shutdown waits for the in-flight check and gives up when its context is
+	// done before the check completes.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, h.Shutdown(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-h.Done():
+		t.Fatal("shutdown completed with an in-flight check")
+	default:
+	}
+
+	close(release)
+	assert.NoError(t, h.Shutdown(context.Background()))
+	<-h.Done()
+}
+
+func TestHealth_ShutdownCancelsChecks(t *testing.T) {
+	type requestIDKey struct{}
+
+	var (
+		mu       sync.Mutex
+		names    []string
+		attempts []int
+		ids      []any
+	)
+	blocked := make(chan struct{})
+	h := New(Component{
+		Name:     "mongo",
+		Critical: true,
+		Timeout:  time.Minute,
+		Interval: 2 * time.Minute,
+		Check: func(ctx context.Context) error {
+			name, _ := ComponentNameFromContext(ctx)
+			attempt, _ := AttemptFromContext(ctx)
+			mu.Lock()
+			names = append(names, name)
+			attempts = append(attempts, attempt)
+			ids = append(ids, ctx.Value(requestIDKey{}))
+			mu.Unlock()
+
+			// The first check succeeds, the second blocks until cancelled.
+			if attempt == 1 {
+				return nil
+			}
+			close(blocked)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "startup")
+	assert.NoError(t, h.Start(ctx))
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, 5*time.Millisecond)
+
+	// Trigger the second check directly rather than waiting for the interval.
+	go h.components[0].check(h.ctx)
+	<-blocked
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, h.Shutdown(shutdownCtx))
+
+	// The cancelled check is discarded so the last known status is retained.
+	status, _ := h.Snapshot(context.Background()).Component("mongo")
+	assert.Equal(t, StatusUp, status.Status)
+	assert.Equal(t, 1, status.ConsecutiveSuccesses)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"mongo", "mongo"}, names)
+	assert.Equal(t, []int{1, 2}, attempts)
+	assert.Equal(t, []any{"startup", "startup"}, ids)
+
+	_, ok := ComponentNameFromContext(context.Background())
+	assert.False(t, ok)
+	_, ok = AttemptFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestHealth_StatusUnknown(t *testing.T) {
+	release := make(chan struct{})
+	h := New(
+		Component{
+			Name:     "redis",
+			Critical: false,
+			Check: func(ctx context.Context) error {
+				return nil
+			},
+		},
+		Component{
+			Name:     "mongo",
+			Critical: true,
+			Check: func(ctx context.Context) error {
+				<-release
+				return nil
+			},
+		},
+	)
+
+	// Until the first check completes every component reports unknown.
+	snapshot := h.Snapshot(context.Background())
+	assert.Equal(t, StatusUnknown, snapshot.Status)
+	for _, c := range snapshot.Components {
+		assert.Equal(t, StatusUnknown, c.Status)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	// The first check is performed immediately on start rather than after the
+	// first interval has elapsed.
+	assert.Eventually(t, func() bool {
+		redis, _ := h.Snapshot(context.Background()).Component("redis")
+		return redis.Status == StatusUp
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, StatusUnknown, h.Status(context.Background()))
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestComponents_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []ComponentStatus
+		expected Status
+	}{
+		{
+			name: "Critical Component Unknown",
+			statuses: []ComponentStatus{
+				{Name: "redis", Critical: false, Status: StatusUp},
+				{Name: "mongo", Critical: true, Status: StatusUnknown},
+			},
+			expected: StatusUnknown,
+		},
+		{
+			name: "Non Critical Component Unknown",
+			statuses: []ComponentStatus{
+				{Name: "redis", Critical: false, Status: StatusUnknown},
+				{Name: "mongo", Critical: true, Status: StatusUp},
+			},
+			expected: StatusDegraded,
+		},
+		{
+			name: "Critical Component Unknown And Non Critical Down",
+			statuses: []ComponentStatus{
+				{Name: "mongo", Critical: true, Status: StatusUnknown},
+				{Name: "redis", Critical: false, Status: StatusDown},
+			},
+			expected: StatusUnknown,
+		},
+		{
+			name: "Critical Component Unknown And Critical Down",
+			statuses: []ComponentStatus{
+				{Name: "mongo", Critical: true, Status: StatusUnknown},
+				{Name: "postgres", Critical: true, Status: StatusDown},
+			},
+			expected: StatusDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, aggregate(tt.statuses))
+		})
+	}
+}
+
+func TestHealth_ResultCheck(t *testing.T) {
+	h := New(
+		Component{
+			Name:     "redis",
+			Critical: true,
+			ResultCheck: func(ctx context.Context) Result {
+				return Result{
+					Status:  StatusDegraded,
+					Message: "replica lag exceeds threshold",
+					Details: map[string]any{
+						"lag_seconds": 12,
+					},
+					Latency: 3 * time.Millisecond,
+				}
+			},
+		},
+		Component{
+			Name:     "mongo",
+			Critical: true,
+			Check: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			},
+		},
+	)
+	for _, c := range h.components {
+		c.check(context.Background())
+	}
+
+	snapshot := h.Snapshot(context.Background())
+	assert.Equal(t, StatusDown, snapshot.Status)
+
+	redis, _ := snapshot.Component("redis")
+	assert.Equal(t, StatusDegraded, redis.Status)
+	assert.Equal(t, "replica lag exceeds threshold", redis.Message)
+	assert.Equal(t, map[string]any{"lag_seconds": 12}, redis.Details)
+	assert.Equal(t, 3*time.Millisecond, redis.Latency)
+
+	mongo, _ := snapshot.Component("mongo")
+	assert.Equal(t, StatusDown, mongo.Status)
+	assert.Equal(t, "connection refused", mongo.Error)
+	assert.Greater(t, mongo.Latency, time.Duration(0))
+
+	// Snapshots are immutable, modifying the details must not affect the state
+	// of the component.
+	redis.Details["lag_seconds"] = 0
+	redis, _ = h.Snapshot(context.Background()).Component("redis")
+	assert.Equal(t, map[string]any{"lag_seconds": 12}, redis.Details)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var res struct {
+		Components []map[string]any `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	redisJSON := res.Components[0]
+	assert.Equal(t, "DEGRADED", redisJSON["status"])
+	assert.Equal(t, "replica lag exceeds threshold", redisJSON["message"])
+	assert.Equal(t, map[string]any{"lag_seconds": float64(12)}, redisJSON["details"])
+	assert.Equal(t, "3ms", redisJSON["latency"])
+}
+
+func TestHealth_CheckHistory(t *testing.T) {
+	var fail atomic.Bool
+	h := New(Component{
+		Name:     "mongo",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			if fail.Load() {
+				return errors.New("dial tcp mongo.internal:27017: connection refused")
+			}
+			return nil
+		},
+	})
+	mongo := h.components[0]
+
+	status, _ := h.Snapshot(context.Background()).Component("mongo")
+	assert.True(t, status.LastChecked.IsZero())
+	assert.True(t, status.LastSuccess.IsZero())
+
+	mongo.check(context.Background())
+	mongo.check(context.Background())
+	status, _ = h.Snapshot(context.Background()).Component("mongo")
+	assert.Equal(t, 2, status.ConsecutiveSuccesses)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Equal(t, status.LastChecked, status.LastSuccess)
+	assert.Greater(t, status.Duration, time.Duration(0))
+	lastSuccess := status.LastSuccess
+
+	fail.Store(true)
+	mongo.check(context.Background())
+	mongo.check(context.Background())
+	mongo.check(context.Background())
+	status, _ = h.Snapshot(context.Background()).Component("mongo")
+	assert.Equal(t, StatusDown, status.Status)
+	assert.Equal(t, 0, status.ConsecutiveSuccesses)
+	assert.Equal(t, 3, status.ConsecutiveFailures)
+	assert.Equal(t, "dial tcp mongo.internal:27017: connection refused", status.Error)
+	assert.Equal(t, lastSuccess, status.LastSuccess)
+	assert.True(t, status.LastChecked.After(lastSuccess))
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		error   string
+	}{
+		{
+			name:    "Default",
+			handler: h,
+			error:   "dial tcp mongo.internal:27017: connection refused",
+		},
+		{
+			name:    "Redacted Errors",
+			handler: h.Handler(WithRedactedErrors()),
+			error:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+			var res struct {
+				Components []ComponentStatus `json:"components"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, tt.error, res.Components[0].Error)
+			assert.Equal(t, 3, res.Components[0].ConsecutiveFailures)
+			assert.True(t, res.Components[0].LastChecked.Equal(status.LastChecked))
+			assert.True(t, res.Components[0].LastSuccess.Equal(status.LastSuccess))
+			assert.Equal(t, status.Duration, res.Components[0].Duration)
+		})
+	}
+}
+
+func TestComponent_Thresholds(t *testing.T) {
+	tests := []struct {
+		name      string
+		component Component
+		reported  []Status
+		expected  []Status
+	}{
+		{
+			name:      "Default Thresholds",
+			component: Component{},
+			reported:  []Status{StatusUp, StatusDown, StatusUp, StatusDegraded},
+			expected:  []Status{StatusUp, StatusDown, StatusUp, StatusDegraded},
+		},
+		{
+			name:      "Failure Threshold",
+			component: Component{FailureThreshold: 3},
+			reported:  []Status{StatusUp, StatusDown, StatusDown, StatusUp, StatusDown, StatusDown, StatusDown},
+			expected:  []Status{StatusUp, StatusUp, StatusUp, StatusUp, StatusUp, StatusUp, StatusDown},
+		},
+		{
+			name:      "First Check Ignores Failure Threshold",
+			component: Component{FailureThreshold: 3},
+			reported:  []Status{StatusDown, StatusDown},
+			expected:  []Status{StatusDown, StatusDown},
+		},
+		{
+			name:      "Success Threshold",
+			component: Component{SuccessThreshold: 2},
+			reported:  []Status{StatusDown, StatusUp, StatusDown, StatusUp, StatusUp, StatusDown},
+			expected:  []Status{StatusDown, StatusDown, StatusDown, StatusDown, StatusUp, StatusDown},
+		},
+		{
+			name:      "Degrade On Failure",
+			component: Component{FailureThreshold: 2, SuccessThreshold: 2, DegradeOnFailure: true},
+			reported:  []Status{StatusUp, StatusDown, StatusUp, StatusDown, StatusDown, StatusDown, StatusUp, StatusUp},
+			expected:  []Status{StatusUp, StatusDegraded, StatusUp, StatusDegraded, StatusDown, StatusDown, StatusDown, StatusUp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.component
+			c.Name = "redis"
+			c.Check = func(ctx context.Context) error {
+				return nil
+			}
+			c.init()
+
+			actual := make([]Status, 0, len(tt.reported))
+			for _, status := range tt.reported {
+				c.record(Result{Status: status}, time.Now(), 0)
+				actual = append(actual, c.snapshot().Status)
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestComponent_NextInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		component Component
+		reported  []Status
+		expected  []time.Duration
+	}{
+		{
+			name:      "Fixed Interval",
+			component: Component{Interval: 10 * time.Second},
+			reported:  []Status{StatusUp, StatusDown, StatusDown, StatusUp},
+			expected:  []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		{
+			name:      "Unhealthy Interval",
+			component: Component{Interval: 10 * time.Second, UnhealthyInterval: 2 * time.Second},
+			reported:  []Status{StatusUp, StatusDown, StatusDown, StatusUp},
+			expected:  []time.Duration{10 * time.Second, 2 * time.Second, 2 * time.Second, 10 * time.Second},
+		},
+		{
+			name:      "Exponential Backoff",
+			component: Component{Interval: 10 * time.Second, MaxBackoff: time.Minute},
+			reported:  []Status{StatusDown, StatusDown, StatusDown, StatusDown, StatusUp},
+			expected:  []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, 10 * time.Second},
+		},
+		{
+			name:      "Exponential Backoff From Unhealthy Interval",
+			component: Component{Interval: 10 * time.Second, UnhealthyInterval: time.Second, MaxBackoff: 5 * time.Second},
+			reported:  []Status{StatusDown, StatusDown, StatusDown, StatusDown},
+			expected:  []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		},
+		{
+			name:      "Backoff Only While Down",
+			component: Component{Interval: 10 * time.Second, MaxBackoff: time.Minute, FailureThreshold: 3},
+			reported:  []Status{StatusUp, StatusDown, StatusDown, StatusDown, StatusDown, StatusDown},
+			expected:  []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.component
+			c.Name = "redis"
+			c.Check = func(ctx context.Context) error {
+				return nil
+			}
+			c.init()
+
+			actual := make([]time.Duration, 0, len(tt.reported))
+			for _, status := range tt.reported {
+				c.record(Result{Status: status}, time.Now(), 0)
+				actual = append(actual, c.nextInterval())
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("Jitter", func(t *testing.T) {
+		c := Component{
+			Name:     "redis",
+			Interval: 10 * time.Second,
+			Jitter:   0.2,
+			Check: func(ctx context.Context) error {
+				return nil
+			},
+		}
+		c.init()
+		c.record(Result{Status: StatusUp}, time.Now(), 0)
+
+		intervals := make(map[time.Duration]struct{})
+		for i := 0; i < 100; i++ {
+			interval := c.nextInterval()
+			assert.GreaterOrEqual(t, interval, 8*time.Second)
+			assert.LessOrEqual(t, interval, 12*time.Second)
+			intervals[interval] = struct{}{}
+		}
+		assert.Greater(t, len(intervals), 1)
+	})
+}
+
+func TestComponent_CheckIsolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   ResultCheckFunc
+		failure FailureReason
+		error   string
+	}{
+		{
+			name: "Panic",
+			check: func(ctx context.Context) Result {
+				panic("nil map write")
+			},
+			failure: FailurePanic,
+			error:   "health: check panicked: nil map write",
+		},
+		{
+			name: "Non Cooperative Timeout",
+			check: func(ctx context.Context) Result {
+				time.Sleep(time.Second)
+				return Result{Status: StatusUp}
+			},
+			failure: FailureTimeout,
+			error:   "health: check timed out after 10ms",
+		},
+		{
+			name: "Cooperative Timeout",
+			check: func(ctx context.Context) Result {
+				<-ctx.Done()
+				return Result{Status: StatusDown, Error: ctx.Err()}
+			},
+			failure: FailureTimeout,
+			error:   "health: check timed out after 10ms",
+		},
+		{
+			name: "Error",
+			check: func(ctx context.Context) Result {
+				return Result{Status: StatusDown, Error: errors.New("connection refused")}
+			},
+			failure: FailureError,
+			error:   "connection refused",
+		},
+		{
+			name: "Success",
+			check: func(ctx context.Context) Result {
+				return Result{Status: StatusUp}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Component{
+				Name:        "redis",
+				Timeout:     10 * time.Millisecond,
+				ResultCheck: tt.check,
+			}
+			c.init()
+
+			start := time.Now()
+			c.check(context.Background())
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+			status := c.snapshot()
+			assert.Equal(t, tt.failure, status.Failure)
+			if tt.error == "" {
+				assert.Empty(t, status.Error)
+			} else {
+				assert.True(t, strings.HasPrefix(status.Error, tt.error), status.Error)
+			}
+			if tt.failure == FailurePanic {
+				assert.Equal(t, StatusDown, status.Status)
+				assert.Equal(t, "nil map write", status.Details["panic"])
+				assert.Contains(t, status.Details["stack"], "runtime/debug.Stack")
+			}
+		})
+	}
+}
+
+func TestComponent_AbandonedChecksDontAccumulate(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	h := New(Component{
+		Name:     "redis",
+		Timeout:  2 * time.Millisecond,
+		Interval: 5 * time.Millisecond,
+		Check: func(ctx context.Context) error {
+			calls.Add(1)
+			<-release
+			return nil
+		},
+	})
+	before := runtime.NumGoroutine()
+	assert.NoError(t, h.Start(context.Background()))
+
+	// The check never returns, so it is abandoned once and the component is
+	// reported as timed out on every subsequent interval without starting
+	// another check.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.LessOrEqual(t, runtime.NumGoroutine()-before, 3)
+	status, _ := h.Snapshot(context.Background()).Component("redis")
+	assert.Equal(t, StatusDown, status.Status)
+	assert.Equal(t, FailureTimeout, status.Failure)
+	assert.Greater(t, status.ConsecutiveFailures, 5)
+
+	// Once the abandoned check returns the component is checked again.
+	close(release)
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp && calls.Load() > 1
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, h.Shutdown(context.Background()))
+}
+
+func TestHealth_RedactedPanic(t *testing.T) {
+	c := Component{
+		Name:    "postgres",
+		Timeout: 10 * time.Millisecond,
+		ResultCheck: func(ctx context.Context) Result {
+			panic("connect postgres://admin:secret@db:5432")
+		},
+	}
+	c.init()
+	c.check(context.Background())
+	comps := Components{&c}
+
+	handlers := map[string]http.Handler{
+		"JSON":     comps.Handler(WithRedactedErrors()),
+		"Actuator": comps.Handler(WithRedactedErrors(), WithActuatorFormat(ShowDetailsAlways())),
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "secret")
+			assert.NotContains(t, rec.Body.String(), "runtime/debug.Stack")
+		})
+	}
+
+	// The panic details are retained when errors are not redacted.
+	rec := httptest.NewRecorder()
+	comps.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Contains(t, rec.Body.String(), "secret")
+	assert.Contains(t, rec.Body.String(), "runtime/debug.Stack")
+}
+
+func TestHealth_Deregister(t *testing.T) {
+	var calls atomic.Int32
+	h := New(
+		Component{
+			Name:     "redis",
+			Timeout:  5 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+			Check: func(ctx context.Context) error {
+				calls.Add(1)
+				return errors.New("redis down")
+			},
+		},
+		Component{
+			Name:     "mongo",
+			Critical: true,
+			Check: func(ctx context.Context) error {
+				return nil
+			},
+		},
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusDegraded
+	}, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, h.Deregister("redis"))
+	assert.ErrorIs(t, h.Deregister("redis"), ErrComponentNotFound)
+
+	snapshot := h.Snapshot(context.Background())
+	assert.Equal(t, StatusUp, snapshot.Status)
+	_, ok := snapshot.Component("redis")
+	assert.False(t, ok)
+
+	// The monitor of the deregistered component has been stopped.
+	time.Sleep(20 * time.Millisecond)
+	n := calls.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, n, calls.Load())
+}
+
+func TestHealth_Replace(t *testing.T) {
+	h := New(Component{
+		Name:     "redis",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return errors.New("connection pool closed")
+		},
+	})
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusDown
+	}, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, h.Replace(Component{
+		Name:     "redis",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return nil
+		},
+	}))
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, 5*time.Millisecond)
+
+	status, _ := h.Snapshot(context.Background()).Component("redis")
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Empty(t, status.Error)
+	assert.Len(t, h.Snapshot(context.Background()).Components, 1)
+
+	assert.ErrorIs(t, h.Replace(Component{
+		Name: "mongo",
+		Check: func(ctx context.Context) error {
+			return nil
+		},
+	}), ErrComponentNotFound)
+}
+
+func TestHealth_Update(t *testing.T) {
+	var calls atomic.Int32
+	h := New(Component{
+		Name:     "redis",
+		Critical: true,
+		Timeout:  time.Second,
+		Interval: time.Minute,
+		Check: func(ctx context.Context) error {
+			calls.Add(1)
+			return errors.New("redis down")
+		},
+	})
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusDown
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+
+	assert.NoError(t, h.Update("redis", func(c *Component) {
+		c.Critical = false
+		c.Timeout = 5 * time.Millisecond
+		c.Interval = 10 * time.Millisecond
+	}))
+
+	// The status is retained but the change in criticality takes effect
+	// immediately, and the updated interval is used for subsequent checks.
+	status, _ := h.Snapshot(context.Background()).Component("redis")
+	assert.Equal(t, StatusDown, status.Status)
+	assert.False(t, status.Critical)
+	assert.Equal(t, StatusDegraded, h.Status(context.Background()))
+	assert.Eventually(t, func() bool {
+		return calls.Load() >= 3
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Error(t, h.Update("redis", func(c *Component) {
+		c.Name = "cache"
+	}))
+	assert.ErrorIs(t, h.Update("mongo", func(c *Component) {}), ErrComponentNotFound)
+
+	// A Timeout that wasn't set is derived from the updated Interval.
+	h.Register(Component{
+		Name: "cache",
+		Check: func(ctx context.Context) error {
+			return nil
+		},
+	})
+	assert.NoError(t, h.Update("cache", func(c *Component) {
+		c.Interval = 2 * time.Second
+	}))
+	assert.Equal(t, time.Second, h.lookup("cache").Timeout)
+
+	// The update function may call the Health instance.
+	assert.NoError(t, h.Update("redis", func(c *Component) {
+		c.Critical = h.Status(context.Background()) != StatusUp
+	}))
+	status, _ = h.Snapshot(context.Background()).Component("redis")
+	assert.True(t, status.Critical)
+}
+
+func TestHealth_UpdateDuringFirstCheck(t *testing.T) {
+	started := make(chan struct{}, 1)
+	var calls atomic.Int32
+	h := New(Component{
+		Name:     "database",
+		Critical: true,
+		Timeout:  time.Second,
+		Interval: 20 * time.Second,
+		Check: func(ctx context.Context) error {
+			if calls.Add(1) == 1 {
+				started <- struct{}{}
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		},
+	})
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+	<-started
+
+	// The result of the first check is discarded by the update, so the updated
+	// component is checked immediately rather than after the Interval.
+	assert.NoError(t, h.Update("database", func(c *Component) {
+		c.Timeout = 2 * time.Second
+	}))
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, time.Millisecond)
+}
+
+func TestHealth_TryRegister(t *testing.T) {
+	check := func(ctx context.Context) error {
+		return nil
+	}
+
+	tests := []struct {
+		name      string
+		component Component
+		expected  error
+	}{
+		{
+			name:      "Valid",
+			component: Component{Name: "mongo", Check: check},
+		},
+		{
+			name:      "Empty Name",
+			component: Component{Name: "  ", Check: check},
+			expected:  ErrEmptyName,
+		},
+		{
+			name:      "Duplicate Name",
+			component: Component{Name: "redis", Check: check},
+			expected:  ErrDuplicateName,
+		},
+		{
+			name:      "Nil Check",
+			component: Component{Name: "mongo"},
+			expected:  ErrNilCheck,
+		},
+		{
+			name:      "Timeout Equal To Interval",
+			component: Component{Name: "mongo", Timeout: time.Second, Interval: time.Second, Check: check},
+			expected:  ErrInvalidTimeout,
+		},
+		{
+			name:      "Timeout Greater Than Default Interval",
+			component: Component{Name: "mongo", Timeout: 30 * time.Second, Check: check},
+			expected:  ErrInvalidTimeout,
+		},
+		{
+			name:      "Interval Less Than Default Timeout",
+			component: Component{Name: "mongo", Interval: 2 * time.Second, Check: check},
+		},
+		{
+			name:      "Negative Interval",
+			component: Component{Name: "mongo", Interval: -time.Second, Check: check},
+			expected:  ErrInvalidTimeout,
+		},
+		{
+			name:      "Negative Threshold",
+			component: Component{Name: "mongo", FailureThreshold: -1, Check: check},
+			expected:  ErrInvalidConfig,
+		},
+		{
+			name:      "Jitter Out Of Range",
+			component: Component{Name: "mongo", Jitter: 1.5, Check: check},
+			expected:  ErrInvalidConfig,
+		},
+		{
+			name:      "Jitter Of Entire Interval",
+			component: Component{Name: "mongo", Jitter: 1, Check: check},
+			expected:  ErrInvalidConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(Component{Name: "redis", Check: check})
+			err := h.TryRegister(tt.component)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				assert.Len(t, h.components, 2)
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.expected)
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.component.Name, validationErr.Component)
+			assert.Len(t, h.components, 1)
+			assert.Panics(t, func() {
+				h.Register(tt.component)
+			})
+		})
+	}
+}
+
+// recordingHandler is a slog.Handler that records the message, level and
+// attributes of every record.
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []map[string]any
+}
+
+func (r *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (r *recordingHandler) WithAttrs([]slog.Attr) slog.Handler       { return r }
+func (r *recordingHandler) WithGroup(string) slog.Handler            { return r }
+
+func (r *recordingHandler) Handle(_ context.Context, record slog.Record) error {
+	entry := map[string]any{
+		"msg":   record.Message,
+		"level": record.Level,
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		entry[attr.Key] = attr.Value.Any()
+		return true
+	})
+	r.mu.Lock()
+	r.records = append(r.records, entry)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *recordingHandler) messages() []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.records)
+}
+
+func TestHealth_Logging(t *testing.T) {
+	var fail atomic.Bool
+	handler := &recordingHandler{}
+	h := NewWithOptions(
+		WithLogger(slog.New(handler)),
+		WithRepeatedFailureLevel(slog.LevelWarn),
+		WithComponents(Component{
+			Name:     "mongo",
+			Critical: true,
+			Timeout:  time.Second,
+			Interval: time.Minute,
+			Check: func(ctx context.Context) error {
+				if fail.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+		}),
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, 5*time.Millisecond)
+
+	mongo := h.registered()[0]
+	mongo.check(context.Background())
+	fail.Store(true)
+	mongo.check(context.Background())
+	mongo.check(context.Background())
+	assert.NoError(t, h.Shutdown(context.Background()))
+
+	// Changes of the overall status are asserted separately.
+	var records, overall []map[string]any
+	for _, record := range handler.messages() {
+		if record["msg"] == "health: status changed" {
+			overall = append(overall, record)
+		} else {
+			records = append(records, record)
+		}
+	}
+	assert.Len(t, records, 6)
+	assert.Len(t, overall, 2)
+
+	assert.Equal(t, "health: component registered", records[0]["msg"])
+	assert.Equal(t, "mongo", records[0]["component"])
+	assert.Equal(t, time.Minute, records[0]["interval"])
+
+	assert.Equal(t, "health: component status changed", records[1]["msg"])
+	assert.Equal(t, slog.LevelInfo, records[1]["level"])
+	assert.Equal(t, "UNKNOWN", records[1]["previous_status"])
+	assert.Equal(t, "UP", records[1]["status"])
+
+	assert.Equal(t, "health: component status changed", records[2]["msg"])
+	assert.Equal(t, slog.LevelError, records[2]["level"])
+	assert.Equal(t, "UP", records[2]["previous_status"])
+	assert.Equal(t, "DOWN", records[2]["status"])
+	assert.Equal(t, "connection refused", records[2]["error"])
+	assert.Contains(t, records[2], "duration")
+
+	assert.Equal(t, "health: component check failed", records[3]["msg"])
+	assert.Equal(t, slog.LevelWarn, records[3]["level"])
+	assert.Equal(t, "connection refused", records[3]["error"])
+
+	assert.Equal(t, "health: shutting down", records[4]["msg"])
+	assert.Equal(t, "health: shutdown complete", records[5]["msg"])
+}
+
+func TestHealth_Watch(t *testing.T) {
+	var redisFail, mongoFail atomic.Bool
+	h := New(
+		Component{
+			Name:     "redis",
+			Critical: false,
+			Timeout:  time.Second,
+			Interval: time.Minute,
+			Check: func(ctx context.Context) error {
+				if redisFail.Load() {
+					return errors.New("redis down")
+				}
+				return nil
+			},
+		},
+		Component{
+			Name:     "mongo",
+			Critical: true,
+			Timeout:  time.Second,
+			Interval: time.Minute,
+			Check: func(ctx context.Context) error {
+				if mongoFail.Load() {
+					return errors.New("mongo down")
+				}
+				return nil
+			},
+		},
+	)
+
+	var (
+		mu                sync.Mutex
+		overall, perComps []StatusChange
+	)
+	h.OnStatusChange(func(sc StatusChange) {
+		mu.Lock()
+		overall = append(overall, sc)
+		mu.Unlock()
+	})
+	h.OnComponentStatusChange(func(sc StatusChange) {
+		// A slow listener must not block monitoring or other listeners.
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		perComps = append(perComps, sc)
+		mu.Unlock()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := h.Watch(ctx)
+
+	assert.NoError(t, h.Start(context.Background()))
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, 5*time.Millisecond)
+
+	redis, mongo := h.registered()[0], h.registered()[1]
+	redisFail.Store(true)
+	redis.check(context.Background())
+	mongoFail.Store(true)
+	mongo.check(context.Background())
+	mongoFail.Store(false)
+	mongo.check(context.Background())
+	assert.NoError(t, h.Shutdown(context.Background()))
+
+	received := make([]StatusChange, 0)
+	for sc := range events {
+		received = append(received, sc)
+	}
+
+	type transition struct {
+		component string
+		previous  Status
+		status    Status
+	}
+	transitions := func(changes []StatusChange) []transition {
+		res := make([]transition, 0, len(changes))
+		for _, sc := range changes {
+			res = append(res, transition{sc.Component, sc.Previous, sc.Status})
+		}
+		return res
+	}
+
+	// The initial checks of both components complete in an arbitrary order, so
+	// only the changes after the application is up are asserted in order.
+	up := slices.IndexFunc(received, func(sc StatusChange) bool {
+		return sc.Overall() && sc.Status == StatusUp
+	})
+	assert.Subset(t, transitions(received[:up]), []transition{
+		{"redis", StatusUnknown, StatusUp},
+		{"mongo", StatusUnknown, StatusUp},
+	})
+	assert.Equal(t, []transition{
+		{"redis", StatusUp, StatusDown},
+		{"", StatusUp, StatusDegraded},
+		{"mongo", StatusUp, StatusDown},
+		{"", StatusDegraded, StatusDown},
+		{"mongo", StatusDown, StatusUp},
+		{"", StatusDown, StatusDegraded},
+	}, transitions(received[up+1:]))
+	assert.Equal(t, "redis down", received[up+1].Error)
+
+	// Listeners are called asynchronously so may still be running.
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(perComps) == 5
+	}, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []transition{
+		{"", StatusUp, StatusDegraded},
+		{"", StatusDegraded, StatusDown},
+		{"", StatusDown, StatusDegraded},
+	}, transitions(overall[len(overall)-3:]))
+	for _, sc := range perComps {
+		assert.False(t, sc.Overall())
+	}
+}
+
+func TestHealth_WatchCancel(t *testing.T) {
+	h := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	events := h.Watch(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("watch channel was not closed after the context was cancelled")
+	}
+}
+
+func TestHealth_RegisterPassive(t *testing.T) {
+	h := New(Component{
+		Name:     "mongo",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return nil
+		},
+	})
+	h.components[0].setStatus(StatusUp)
+
+	breaker, err := h.RegisterPassive(PassiveComponent{
+		Name:          "payments-circuit-breaker",
+		Critical:      false,
+		InitialStatus: StatusUp,
+	})
+	assert.NoError(t, err)
+	consumer, err := h.RegisterPassive(PassiveComponent{
+		Name:     "kafka-consumer",
+		Critical: true,
+	})
+	assert.NoError(t, err)
+
+	// Passive components are never checked, so starting Health doesn't change
+	// their status.
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	status, _ := h.Snapshot(context.Background()).Component("kafka-consumer")
+	assert.Equal(t, StatusUnknown, status.Status)
+	assert.Equal(t, StatusUnknown, h.Status(context.Background()))
+
+	consumer.SetUp()
+	assert.Equal(t, StatusUp, h.Status(context.Background()))
+
+	breaker.SetDown(errors.New("circuit breaker open"))
+	status, _ = h.Snapshot(context.Background()).Component("payments-circuit-breaker")
+	assert.Equal(t, StatusDown, status.Status)
+	assert.Equal(t, "circuit breaker open", status.Error)
+	assert.Equal(t, StatusDegraded, h.Status(context.Background()))
+
+	consumer.SetResult(Result{
+		Status:  StatusDown,
+		Message: "consumer fenced",
+		Details: map[string]any{"generation": 42},
+	})
+	status, _ = h.Snapshot(context.Background()).Component("kafka-consumer")
+	assert.Equal(t, "consumer fenced", status.Message)
+	assert.Equal(t, StatusDown, h.Status(context.Background()))
+
+	consumer.SetStatus(StatusDegraded)
+	breaker.SetUp()
+	assert.Equal(t, StatusDegraded, h.Status(context.Background()))
+
+	_, err = h.RegisterPassive(PassiveComponent{Name: "kafka-consumer"})
+	assert.ErrorIs(t, err, ErrDuplicateName)
+	_, err = h.RegisterPassive(PassiveComponent{Name: ""})
+	assert.ErrorIs(t, err, ErrEmptyName)
+	_, err = h.RegisterPassive(PassiveComponent{Name: "worker", TTL: -time.Second})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestPassiveHandle_TTL(t *testing.T) {
+	h := New()
+	worker, err := h.RegisterPassive(PassiveComponent{
+		Name:          "worker",
+		Critical:      true,
+		InitialStatus: StatusUp,
+		TTL:           50 * time.Millisecond,
+		ExpiredStatus: StatusDown,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusUp, h.Status(context.Background()))
+
+	// Refreshing the status before the TTL elapses keeps the status.
+	for i := 0; i < 3; i++ {
+		time.Sleep(25 * time.Millisecond)
+		worker.SetUp()
+	}
+	assert.Equal(t, StatusUp, h.Status(context.Background()))
+
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusDown
+	}, time.Second, 5*time.Millisecond)
+	status, _ := h.Snapshot(context.Background()).Component("worker")
+	assert.Equal(t, "status not set within 50ms", status.Message)
+
+	worker.SetUp()
+	assert.Equal(t, StatusUp, h.Status(context.Background()))
+}
+
+func TestHealth_RegisterHeartbeat(t *testing.T) {
+	h := New()
+	job, err := h.RegisterHeartbeat(HeartbeatComponent{
+		Name:          "reconciliation-job",
+		Critical:      true,
+		DegradedAfter: 40 * time.Millisecond,
+		DownAfter:     80 * time.Millisecond,
+		Interval:      10 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, time.Millisecond)
+
+	status, _ := h.Snapshot(context.Background()).Component("reconciliation-job")
+	assert.Contains(t, status.Details, "since_last_beat")
+	assert.NotContains(t, status.Details, "last_beat")
+
+	// Without beats the component degrades, and then goes down.
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusDegraded
+	}, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusDown
+	}, time.Second, time.Millisecond)
+	status, _ = h.Snapshot(context.Background()).Component("reconciliation-job")
+	assert.Equal(t, "no heartbeat received within 80ms", status.Message)
+
+	job.Beat()
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, time.Millisecond)
+	status, _ = h.Snapshot(context.Background()).Component("reconciliation-job")
+	assert.Contains(t, status.Details, "last_beat")
+
+	tests := []struct {
+		name      string
+		component HeartbeatComponent
+		expected  error
+	}{
+		{
+			name:      "Missing Down After",
+			component: HeartbeatComponent{Name: "job"},
+			expected:  ErrInvalidConfig,
+		},
+		{
+			name:      "Degraded After Not Less Than Down After",
+			component: HeartbeatComponent{Name: "job", DegradedAfter: time.Minute, DownAfter: time.Minute},
+			expected:  ErrInvalidConfig,
+		},
+		{
+			name:      "Duplicate Name",
+			component: HeartbeatComponent{Name: "reconciliation-job", DownAfter: time.Minute},
+			expected:  ErrDuplicateName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := h.RegisterHeartbeat(tt.component)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestHealth_OnDemandChecks(t *testing.T) {
+	var checks atomic.Int32
+	h := NewWithOptions(
+		WithOnDemandChecks(time.Second),
+		WithComponents(Component{
+			Name:     "database",
+			Critical: true,
+			CacheTTL: time.Hour,
+			Check: func(ctx context.Context) error {
+				checks.Add(1)
+				time.Sleep(50 * time.Millisecond)
+				return nil
+			},
+		}),
+	)
+
+	// Components are not checked before the Health instance is started.
+	assert.Equal(t, StatusUnknown, h.Status(context.Background()))
+	assert.Equal(t, int32(0), checks.Load())
+
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	// Nothing is checked in the background.
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), checks.Load())
+
+	// Concurrent requests share a single check.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, StatusUp, h.Status(context.Background()))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), checks.Load())
+
+	// The result is cached for the CacheTTL of the component.
+	assert.Equal(t, StatusUp, h.Status(context.Background()))
+	assert.Equal(t, int32(1), checks.Load())
+
+	assert.NoError(t, h.Update("database", func(c *Component) {
+		c.CacheTTL = 0
+	}))
+	assert.Equal(t, StatusUp, h.Status(context.Background()))
+	assert.Equal(t, int32(2), checks.Load())
+}
+
+func TestHealth_OnDemandChecksTimeout(t *testing.T) {
+	release := make(chan struct{})
+	h := NewWithOptions(
+		WithOnDemandChecks(20*time.Millisecond),
+		WithComponents(
+			Component{
+				Name:     "database",
+				Critical: true,
+				Check: func(ctx context.Context) error {
+					return nil
+				},
+			},
+			Component{
+				Name:     "slow",
+				Critical: true,
+				Check: func(ctx context.Context) error {
+					<-release
+					return nil
+				},
+			},
+		),
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	start := time.Now()
+	snapshot := h.Snapshot(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, StatusUnknown, snapshot.Status)
+	database, _ := snapshot.Component("database")
+	assert.Equal(t, StatusUp, database.Status)
+	slow, _ := snapshot.Component("slow")
+	assert.Equal(t, StatusUnknown, slow.Status)
+
+	// The abandoned check still updates the status of the component once it
+	// completes.
+	close(release)
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, time.Millisecond)
+}
+
+func TestHealth_Refresh(t *testing.T) {
+	var checks atomic.Int32
+	h := New(Component{
+		Name:     "database",
+		Critical: true,
+		Interval: time.Hour,
+		Check: func(ctx context.Context) error {
+			checks.Add(1)
+			return nil
+		},
+	})
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+	assert.Eventually(t, func() bool {
+		return checks.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int32(1), checks.Load())
+
+	// Refreshing must be enabled explicitly.
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health?refresh=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int32(1), checks.Load())
+
+	rr = httptest.NewRecorder()
+	h.Handler(WithRefresh()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health?refresh=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int32(2), checks.Load())
+}
+
+func TestHealth_RefreshSharesScheduledCheck(t *testing.T) {
+	var running, concurrent atomic.Int32
+	release := make(chan struct{})
+	h := New(Component{
+		Name:     "database",
+		Timeout:  time.Second,
+		Interval: time.Hour,
+		Check: func(ctx context.Context) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			if n > concurrent.Load() {
+				concurrent.Store(n)
+			}
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+	assert.Eventually(t, func() bool {
+		return running.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	// A refresh while the scheduled check is in progress waits for it rather
+	// than checking the component concurrently.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rr := httptest.NewRecorder()
+		h.Handler(WithRefresh()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health?refresh=true", nil))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	<-done
+	assert.Equal(t, int32(1), concurrent.Load())
+}
+
+func TestHealth_UpdateCancelsOnDemandCheck(t *testing.T) {
+	started := make(chan struct{}, 1)
+	var calls atomic.Int32
+	h := NewWithOptions(
+		WithOnDemandChecks(0),
+		WithComponents(Component{
+			Name:     "database",
+			Critical: true,
+			Timeout:  time.Second,
+			Interval: time.Hour,
+			Check: func(ctx context.Context) error {
+				if calls.Add(1) == 1 {
+					started <- struct{}{}
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				return nil
+			},
+		}),
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.Status(ctx)
+	<-started
+
+	// Updating the component cancels the check using the old configuration, so
+	// its result is discarded rather than recorded.
+	assert.NoError(t, h.Update("database", func(c *Component) {
+		c.Critical = false
+	}))
+	status, _ := h.Snapshot(context.Background()).Component("database")
+	assert.Equal(t, StatusUp, status.Status)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+}
+
+func TestComponents_Refresh(t *testing.T) {
+	var healthy atomic.Bool
+	database := &Component{
+		Name:     "database",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			if !healthy.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}
+	assert.NoError(t, database.init())
+	components := Components{database}
+
+	rr := httptest.NewRecorder()
+	components.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	healthy.Store(true)
+	rr = httptest.NewRecorder()
+	components.Handler(WithRefresh()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health?refresh=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, StatusUp, components.Status(context.Background()))
+}
+
+func TestHealth_ComponentRoutes(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	var checks atomic.Int32
+	h := New(
+		Component{
+			Name:     "database",
+			Critical: true,
+			Interval: time.Hour,
+			Check: func(ctx context.Context) error {
+				checks.Add(1)
+				if !healthy.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+		},
+		Component{
+			Name:     "cache",
+			Interval: time.Hour,
+			Check: func(ctx context.Context) error {
+				return nil
+			},
+		},
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, time.Millisecond)
+
+	handler := h.Handler(WithComponentRoutes("/health"), WithRefresh())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var overall map[string]any
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &overall))
+	assert.Len(t, overall["components"], 2)
+
+	healthy.Store(false)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health/database", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var status ComponentStatus
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	assert.Equal(t, "database", status.Name)
+	assert.Equal(t, StatusUp, status.Status)
+	assert.Equal(t, int32(1), checks.Load())
+
+	// A POST request re-checks the component before responding.
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/health/database", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	assert.Equal(t, StatusDown, status.Status)
+	assert.Equal(t, "connection refused", status.Error)
+	assert.Equal(t, int32(2), checks.Load())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health/queue", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/health/database", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, "GET, HEAD, POST", rr.Header().Get("Allow"))
+
+	// Without refreshing enabled components cannot be checked with a POST.
+	rr = httptest.NewRecorder()
+	h.Handler(WithComponentRoutes("/health")).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/health/database", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, "GET, HEAD", rr.Header().Get("Allow"))
+	assert.Equal(t, int32(2), checks.Load())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// With the prefix stripped the base path is empty.
+	stripped := http.StripPrefix("/health", h.Handler(WithComponentRoutes("")))
+	rr = httptest.NewRecorder()
+	stripped.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health/cache", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	assert.Equal(t, "cache", status.Name)
+}
+
+func TestHealth_DependsOn(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	var checks atomic.Int32
+	h := New(
+		Component{
+			Name:     "postgres",
+			Critical: true,
+			Interval: 20 * time.Millisecond,
+			Timeout:  10 * time.Millisecond,
+			Check: func(ctx context.Context) error {
+				if !healthy.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+		},
+		Component{
+			Name:      "orders-api",
+			Interval:  20 * time.Millisecond,
+			Timeout:   10 * time.Millisecond,
+			DependsOn: []string{"postgres"},
+			Check: func(ctx context.Context) error {
+				checks.Add(1)
+				return nil
+			},
+		},
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, time.Millisecond)
+
+	healthy.Store(false)
+	assert.Eventually(t, func() bool {
+		status, _ := h.Snapshot(context.Background()).Component("orders-api")
+		return status.Status == StatusUnknown
+	}, time.Second, time.Millisecond)
+
+	snapshot := h.Snapshot(context.Background())
+	assert.Equal(t, StatusDown, snapshot.Status)
+	status, _ := snapshot.Component("orders-api")
+	assert.Equal(t, `dependency "postgres" unavailable`, status.Error)
+	assert.Equal(t, FailureDependency, status.Failure)
+	assert.Equal(t, []string{"postgres"}, status.DependsOn)
+
+	// The dependent is not checked while its dependency is down.
+	n := checks.Load()
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, n, checks.Load())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Contains(t, rr.Body.String(), `"dependsOn":["postgres"]`)
+
+	healthy.Store(true)
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, time.Millisecond)
+	assert.Greater(t, checks.Load(), n)
+
+	// A dependency cannot be deregistered before its dependents.
+	assert.ErrorIs(t, h.Deregister("postgres"), ErrHasDependents)
+	assert.Len(t, h.Snapshot(context.Background()).Components, 2)
+	assert.NoError(t, h.Deregister("orders-api"))
+	assert.NoError(t, h.Deregister("postgres"))
+}
+
+func TestHealth_OnDemandDependencyOrder(t *testing.T) {
+	var healthy atomic.Bool
+	h := NewWithOptions(
+		WithOnDemandChecks(time.Second),
+		WithComponents(
+			// The dependent is registered before its dependency.
+			Component{
+				Name:      "orders-api",
+				DependsOn: []string{"postgres"},
+				Check: func(ctx context.Context) error {
+					return nil
+				},
+			},
+			Component{
+				Name:     "postgres",
+				Critical: true,
+				Check: func(ctx context.Context) error {
+					time.Sleep(20 * time.Millisecond)
+					if !healthy.Load() {
+						return errors.New("connection refused")
+					}
+					return nil
+				},
+			},
+		),
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	snapshot := h.Snapshot(context.Background())
+	assert.Equal(t, StatusDown, snapshot.Status)
+	status, _ := snapshot.Component("orders-api")
+	assert.Equal(t, FailureDependency, status.Failure)
+
+	// The dependent awaits the check of its dependency rather than reading the
+	// status of its previous check.
+	healthy.Store(true)
+	snapshot = h.Snapshot(context.Background())
+	assert.Equal(t, StatusUp, snapshot.Status)
+	status, _ = snapshot.Component("orders-api")
+	assert.Equal(t, StatusUp, status.Status)
+	assert.Empty(t, status.Error)
+}
+
+func TestHealth_DependencyCycle(t *testing.T) {
+	check := func(ctx context.Context) error {
+		return nil
+	}
+	h := New(
+		Component{Name: "a", Check: check, DependsOn: []string{"b"}},
+		Component{Name: "b", Check: check, DependsOn: []string{"c"}},
+	)
+
+	err := h.TryRegister(Component{Name: "c", Check: check, DependsOn: []string{"a"}})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.EqualError(t, err, `health: invalid component "c": dependency cycle: c -> a -> b -> c`)
+
+	err = h.TryRegister(Component{Name: "self", Check: check, DependsOn: []string{"self"}})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+
+	assert.NoError(t, h.TryRegister(Component{Name: "c", Check: check}))
+	err = h.Update("c", func(c *Component) {
+		c.DependsOn = []string{"b"}
+	})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.Len(t, h.Snapshot(context.Background()).Components, 3)
+}
+
+func TestHealth_Probes(t *testing.T) {
+	var migrated, cacheHealthy atomic.Bool
+	var deadlocked atomic.Bool
+	var checks atomic.Int32
+	h := New(
+		Component{
+			Name:     "event-loop",
+			Critical: true,
+			Groups:   []string{GroupLiveness},
+			Interval: 10 * time.Millisecond,
+			Timeout:  5 * time.Millisecond,
+			Check: func(ctx context.Context) error {
+				if deadlocked.Load() {
+					return errors.New("event loop blocked")
+				}
+				return nil
+			},
+		},
+		Component{
+			Name:     "migrations",
+			Critical: true,
+			Groups:   []string{GroupStartup},
+			Interval: 10 * time.Millisecond,
+			Timeout:  5 * time.Millisecond,
+			Check: func(ctx context.Context) error {
+				if !migrated.Load() {
+					return errors.New("migrations pending")
+				}
+				return nil
+			},
+		},
+		Component{
+			Name:     "database",
+			Critical: true,
+			Groups:   []string{GroupReadiness, GroupStartup},
+			Interval: 10 * time.Millisecond,
+			Timeout:  5 * time.Millisecond,
+			Check: func(ctx context.Context) error {
+				checks.Add(1)
+				return nil
+			},
+		},
+		Component{
+			Name:     "cache",
+			Critical: true,
+			Interval: 10 * time.Millisecond,
+			Timeout:  5 * time.Millisecond,
+			Check: func(ctx context.Context) error {
+				if !cacheHealthy.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+		},
+	)
+	mux := http.NewServeMux()
+	h.RegisterProbes(mux)
+
+	probe := func(path string) (int, []string) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		var res struct {
+			Components []ComponentStatus `json:"components"`
+		}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		names := make([]string, 0, len(res.Components))
+		for _, c := range res.Components {
+			names = append(names, c.Name)
+		}
+		return rr.Code, names
+	}
+
+	assert.NoError(t, h.Start(context.Background()))
+	assert.Eventually(t, func() bool {
+		code, _ := probe("/livez")
+		return code == http.StatusOK
+	}, time.Second, time.Millisecond)
+
+	// The cache only belongs to the readiness group, so its outage doesn't fail
+	// the liveness probe.
+	code, names := probe("/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"event-loop"}, names)
+	code, names = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []string{"database", "cache"}, names)
+	code, names = probe("/startupz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []string{"migrations", "database"}, names)
+
+	// Excluding the pending migrations passes the startup probe, but doesn't
+	// complete startup.
+	assert.Eventually(t, func() bool {
+		code, _ := probe("/startupz?exclude=migrations")
+		return code == http.StatusOK
+	}, time.Second, time.Millisecond)
+	code, _ = probe("/startupz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	migrated.Store(true)
+	cacheHealthy.Store(true)
+	assert.Eventually(t, func() bool {
+		code, _ := probe("/startupz")
+		return code == http.StatusOK
+	}, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		code, _ := probe("/readyz")
+		return code == http.StatusOK
+	}, time.Second, time.Millisecond)
+
+	// Once started, the startup probe keeps succeeding.
+	migrated.Store(false)
+	assert.Eventually(t, func() bool {
+		s, _ := h.Snapshot(context.Background()).Component("migrations")
+		return s.Status == StatusDown
+	}, time.Second, time.Millisecond)
+	code, _ = probe("/startupz")
+	assert.Equal(t, http.StatusOK, code)
+
+	deadlocked.Store(true)
+	assert.Eventually(t, func() bool {
+		code, _ := probe("/livez")
+		return code == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+
+	// The database belongs to two groups but is checked once per interval.
+	n := checks.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.LessOrEqual(t, checks.Load()-n, int32(6))
+
+	// The readiness probe fails once shutting down.
+	assert.NoError(t, h.Shutdown(context.Background()))
+	code, _ = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestComponents_TextOutput(t *testing.T) {
+	components := make(Components, 0)
+	for _, c := range []Component{
+		{Name: "database", Critical: true, Check: func(ctx context.Context) error { return nil }},
+		{Name: "cache", Critical: true, Check: func(ctx context.Context) error { return nil }},
+		{Name: "search", Check: func(ctx context.Context) error { return nil }},
+	} {
+		c := c
+		assert.NoError(t, c.init())
+		components = append(components, &c)
+	}
+	components[0].setStatus(StatusUp)
+	components[1].record(Result{Status: StatusDown, Error: errors.New("connection refused")}, time.Now(), time.Millisecond)
+	components[2].record(Result{Status: StatusDegraded, Message: "high latency"}, time.Now(), time.Millisecond)
+
+	handler := components.Handler(WithTextOutput())
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr
+	}
+
+	rr := get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "text/plain;charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "[+]database ok\n[-]cache failed: connection refused\n[+]search degraded: high latency\nhealth check failed\n", rr.Body.String())
+
+	rr = get("/readyz?exclude=cache")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "ok\n", rr.Body.String())
+
+	rr = get("/readyz?exclude=cache&verbose")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "[+]database ok\n[+]search degraded: high latency\nhealth check passed\n", rr.Body.String())
+
+	rr = get("/readyz?exclude=cache&exclude=search&verbose")
+	assert.Equal(t, "[+]database ok\nhealth check passed\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	components.Handler(WithTextOutput(), WithRedactedErrors(), WithGroup(GroupReadiness)).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, "[+]database ok\n[-]cache failed: reason withheld\n[+]search degraded: reason withheld\nreadiness check failed\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	components.Handler(WithTextOutput(), WithComponentRoutes("/readyz")).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz/cache", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "[-]cache failed: connection refused\n", rr.Body.String())
+}
+
+func TestAggregators(t *testing.T) {
+	statuses := func(s ...Status) []ComponentStatus {
+		components := make([]ComponentStatus, 0, len(s))
+		for i, status := range s {
+			components = append(components, ComponentStatus{
+				Name:   fmt.Sprintf("replica-%d", i),
+				Status: status,
+			})
+		}
+		return components
+	}
+
+	tests := []struct {
+		name       string
+		aggregator Aggregator
+		components []ComponentStatus
+		expected   Status
+	}{
+		{
+			name:       "Default Non-Critical Down",
+			aggregator: DefaultAggregator(),
+			components: statuses(StatusUp, StatusDown),
+			expected:   StatusDegraded,
+		},
+		{
+			name:       "Worst Of Non-Critical Down",
+			aggregator: WorstOfAggregator(),
+			components: statuses(StatusUp, StatusDown),
+			expected:   StatusDown,
+		},
+		{
+			name:       "Worst Of Unknown",
+			aggregator: WorstOfAggregator(),
+			components: statuses(StatusDegraded, StatusUnknown, StatusUp),
+			expected:   StatusUnknown,
+		},
+		{
+			name:       "Quorum Met",
+			aggregator: QuorumAggregator(2),
+			components: statuses(StatusUp, StatusUp, StatusUp),
+			expected:   StatusUp,
+		},
+		{
+			name:       "Quorum Met With Replica Down",
+			aggregator: QuorumAggregator(2),
+			components: statuses(StatusUp, StatusDown, StatusUp),
+			expected:   StatusDegraded,
+		},
+		{
+			name:       "Quorum Not Met",
+			aggregator: QuorumAggregator(2),
+			components: statuses(StatusUp, StatusDown, StatusUnknown),
+			expected:   StatusDown,
+		},
+		{
+			name:       "Quorum Of Named Components",
+			aggregator: QuorumAggregator(1, "replica-0", "replica-1"),
+			components: append(statuses(StatusDown, StatusUp), ComponentStatus{Name: "database", Critical: true, Status: StatusDown}),
+			expected:   StatusDown,
+		},
+		{
+			name:       "Quorum Without Named Components",
+			aggregator: QuorumAggregator(2, "redis-0", "redis-1", "redis-2"),
+			components: statuses(StatusUp),
+			expected:   StatusUp,
+		},
+		{
+			name:       "Quorum Of Fewer Named Components",
+			aggregator: QuorumAggregator(2, "replica-0", "replica-1", "replica-2"),
+			components: statuses(StatusUp),
+			expected:   StatusUp,
+		},
+		{
+			name:       "Percentage Up",
+			aggregator: PercentageAggregator(0.75, 0.5),
+			components: statuses(StatusUp, StatusUp, StatusUp, StatusDown),
+			expected:   StatusUp,
+		},
+		{
+			name:       "Percentage Degraded",
+			aggregator: PercentageAggregator(0.75, 0.5),
+			components: statuses(StatusUp, StatusUp, StatusDegraded, StatusDown),
+			expected:   StatusDegraded,
+		},
+		{
+			name:       "Percentage Down",
+			aggregator: PercentageAggregator(0.75, 0.5),
+			components: statuses(StatusUp, StatusDown, StatusDown, StatusDown),
+			expected:   StatusDown,
+		},
+		{
+			name:       "Weighted Up",
+			aggregator: WeightedAggregator(map[string]float64{"replica-0": 3}, 0.7, 0.3),
+			components: statuses(StatusUp, StatusDown),
+			expected:   StatusUp,
+		},
+		{
+			name:       "Weighted Degraded",
+			aggregator: WeightedAggregator(map[string]float64{"replica-0": 3}, 0.7, 0.3),
+			components: statuses(StatusDegraded, StatusDown),
+			expected:   StatusDegraded,
+		},
+		{
+			name:       "Weighted Down",
+			aggregator: WeightedAggregator(map[string]float64{"replica-0": 3}, 0.7, 0.3),
+			components: statuses(StatusDown, StatusUp),
+			expected:   StatusDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.aggregator.Aggregate(tt.components))
+		})
+	}
+}
+
+func TestHealth_WithAggregator(t *testing.T) {
+	h := NewWithOptions(
+		WithAggregator(WorstOfAggregator()),
+		WithComponents(
+			Component{Name: "database", Critical: true, Check: func(ctx context.Context) error { return nil }},
+			Component{Name: "cache", Check: func(ctx context.Context) error { return errors.New("connection refused") }},
+		),
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusDown
+	}, time.Second, time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"DOWN"`)
+}
+
+func TestHealth_AggregatorProbes(t *testing.T) {
+	check := func(ctx context.Context) error {
+		return nil
+	}
+	h := NewWithOptions(
+		WithAggregator(QuorumAggregator(2, "redis-0", "redis-1", "redis-2")),
+		WithComponents(
+			Component{Name: "event-loop", Groups: []string{GroupLiveness}, Check: check},
+			Component{Name: "redis-0", Check: check},
+			Component{Name: "redis-1", Check: check},
+			Component{Name: "redis-2", Check: check},
+		),
+	)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusUp
+	}, time.Second, time.Millisecond)
+
+	mux := http.NewServeMux()
+	h.RegisterProbes(mux)
+	mux.Handle("/health", h)
+
+	// The quorum only applies to the members being aggregated, so neither a
+	// group without members nor excluding members fails the probe.
+	for _, path := range []string{"/livez", "/readyz", "/health?exclude=redis-0&exclude=redis-1"} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rr.Code, path)
+		assert.Contains(t, rr.Body.String(), `"status":"UP"`, path)
+	}
+}
+
+func TestHealth_Severity(t *testing.T) {
+	h := New(
+		Component{Name: "database", Critical: true, Check: func(ctx context.Context) error { return nil }},
+		Component{Name: "search", Severity: SeverityMinor, Check: func(ctx context.Context) error { return nil }},
+		Component{Name: "feature-flags", Severity: SeverityInfo, Check: func(ctx context.Context) error { return nil }},
+	)
+	snapshot := h.Snapshot(context.Background())
+	database, _ := snapshot.Component("database")
+	assert.Equal(t, SeverityCritical, database.Severity)
+	assert.True(t, database.Critical)
+	search, _ := snapshot.Component("search")
+	assert.Equal(t, SeverityMinor, search.Severity)
+	assert.False(t, search.Critical)
+
+	h.components[0].setStatus(StatusUp)
+	h.components[1].setStatus(StatusUp)
+	assert.Equal(t, StatusUp, h.Status(context.Background()))
+
+	// Informational components never affect the overall status.
+	h.components[2].setStatus(StatusDown)
+	assert.Equal(t, StatusUp, h.Status(context.Background()))
+
+	h.components[1].setStatus(StatusDown)
+	assert.Equal(t, StatusDegraded, h.Status(context.Background()))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Contains(t, rr.Body.String(), `"severity":"info"`)
+
+	// The severity of a critical component can't be lowered.
+	err := h.TryRegister(Component{Name: "cache", Critical: true, Severity: SeverityMinor, Check: func(ctx context.Context) error { return nil }})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	err = h.TryRegister(Component{Name: "cache", Severity: "fatal", Check: func(ctx context.Context) error { return nil }})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	assert.NoError(t, h.Update("search", func(c *Component) {
+		c.Severity = SeverityCritical
+	}))
+	assert.Equal(t, StatusDown, h.Status(context.Background()))
+	search, _ = h.Snapshot(context.Background()).Component("search")
+	assert.True(t, search.Critical)
+
+	// Changing only Critical derives the severity from it.
+	assert.NoError(t, h.Update("search", func(c *Component) {
+		c.Critical = false
+	}))
+	search, _ = h.Snapshot(context.Background()).Component("search")
+	assert.Equal(t, SeverityMajor, search.Severity)
+	assert.Equal(t, StatusDegraded, h.Status(context.Background()))
+
+	// Changing only the severity of a critical component derives Critical.
+	assert.NoError(t, h.Update("database", func(c *Component) {
+		c.Severity = SeverityMinor
+	}))
+	database, _ = h.Snapshot(context.Background()).Component("database")
+	assert.Equal(t, SeverityMinor, database.Severity)
+	assert.False(t, database.Critical)
+
+	// Conflicting changes are rejected.
+	err = h.Update("database", func(c *Component) {
+		c.Critical = true
+		c.Severity = SeverityInfo
+	})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestStatus_Registry(t *testing.T) {
+	warming := Status("WARMING_UP")
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(registry, warming)
+	})
+	assert.False(t, warming.Valid())
+	assert.Equal(t, http.StatusServiceUnavailable, warming.HttpStatusCode())
+
+	_, err := ParseStatus("warming_up")
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+
+	assert.NoError(t, RegisterStatus(warming, StatusConfig{
+		HTTPStatusCode:  http.StatusTooManyRequests,
+		Severity:        StatusDegraded.severity() + 50,
+		PrometheusValue: 1,
+	}))
+	assert.ErrorIs(t, RegisterStatus("warming", StatusConfig{HTTPStatusCode: http.StatusOK}), ErrInvalidStatus)
+	assert.ErrorIs(t, RegisterStatus("COLD", StatusConfig{HTTPStatusCode: 999}), ErrInvalidStatus)
+
+	status, err := ParseStatus("warming_up")
+	assert.NoError(t, err)
+	assert.Equal(t, warming, status)
+	assert.Equal(t, http.StatusTooManyRequests, warming.HttpStatusCode())
+
+	status, err = ParseStatus("out_of_service")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusOutOfService, status)
+
+	var res struct {
+		Status Status `json:"status"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":"maintenance"}`), &res))
+	assert.Equal(t, StatusMaintenance, res.Status)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"status":"SIDEWAYS"}`), &res), ErrInvalidStatus)
+
+	// Marshalling never fails, so encoding a response can't be interrupted.
+	b, err := json.Marshal(Status("SIDEWAYS"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"SIDEWAYS"`, string(b))
+
+	// A critical component in a custom status contributes that status, while a
+	// non-critical component can at most degrade the overall status.
+	tests := []struct {
+		name       string
+		components []ComponentStatus
+		expected   Status
+	}{
+		{
+			name: "Critical Custom Status",
+			components: []ComponentStatus{
+				{Name: "database", Critical: true, Status: StatusUp},
+				{Name: "cache", Critical: true, Status: warming},
+			},
+			expected: warming,
+		},
+		{
+			name: "Down Worse Than Custom Status",
+			components: []ComponentStatus{
+				{Name: "database", Critical: true, Status: StatusDown},
+				{Name: "cache", Critical: true, Status: warming},
+			},
+			expected: StatusDown,
+		},
+		{
+			name: "Critical Out Of Service",
+			components: []ComponentStatus{
+				{Name: "database", Critical: true, Status: StatusOutOfService},
+				{Name: "cache", Critical: true, Status: StatusUnknown},
+			},
+			expected: StatusOutOfService,
+		},
+		{
+			name: "Non-Critical Maintenance",
+			components: []ComponentStatus{
+				{Name: "database", Critical: true, Status: StatusUp},
+				{Name: "search", Status: StatusMaintenance},
+			},
+			expected: StatusDegraded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DefaultAggregator().Aggregate(tt.components))
+		})
+	}
+}
+
+func TestHealth_UnregisteredStatus(t *testing.T) {
+	h := New(Component{
+		Name:     "database",
+		Critical: true,
+		ResultCheck: func(ctx context.Context) Result {
+			return Result{Status: "BROKEN"}
+		},
+	})
+	breaker, err := h.RegisterPassive(PassiveComponent{Name: "breaker"})
+	assert.NoError(t, err)
+	assert.NoError(t, h.Start(context.Background()))
+	defer h.Shutdown(context.Background())
+
+	// An unregistered status is recorded as down rather than failing to encode
+	// the response.
+	breaker.SetStatus("open")
+	assert.Eventually(t, func() bool {
+		return h.Status(context.Background()) == StatusDown
+	}, time.Second, time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	var res struct {
+		Status     Status            `json:"status"`
+		Components []ComponentStatus `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, StatusDown, res.Status)
+	for _, component := range res.Components {
+		assert.Equal(t, StatusDown, component.Status)
+		assert.Contains(t, component.Error, "invalid status")
+	}
+
+	_, err = h.RegisterPassive(PassiveComponent{Name: "consumer", InitialStatus: "fenced"})
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+}
+
+func TestHealth_HandlerStatusCodes(t *testing.T) {
+	h := New(
+		Component{Name: "database", Critical: true, Check: func(ctx context.Context) error { return nil }},
+		Component{Name: "cache", Check: func(ctx context.Context) error { return nil }},
+	)
+	h.components[0].setStatus(StatusUp)
+	h.components[1].setStatus(StatusDown)
+
+	handler := h.Handler(
+		WithStatusCode(StatusDegraded, http.StatusMultiStatus),
+		WithStatusCode(StatusDown, http.StatusInternalServerError),
+		WithRetryAfter(1500*time.Millisecond),
+	)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusMultiStatus, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), `"status":"DEGRADED"`)
+
+	// A HEAD request returns the same status code and headers without a body.
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodHead, "/health", nil))
+	assert.Equal(t, http.StatusMultiStatus, rr.Code)
+	assert.Equal(t, "application/json;charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Empty(t, rr.Body.String())
+
+	h.components[0].setStatus(StatusDown)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+
+	// Without an override the status code of the status is used.
+	rr = httptest.NewRecorder()
+	h.Handler(WithRetryAfter(1500*time.Millisecond)).ServeHTTP(rr, httptest.NewRequest(http.MethodHead, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.Empty(t, rr.Body.String())
+
+	// The text output considers the overridden status code when determining
+	// whether the check passed.
+	h.components[0].setStatus(StatusUp)
+	rr = httptest.NewRecorder()
+	h.Handler(WithTextOutput(), WithStatusCode(StatusDegraded, http.StatusTooManyRequests)).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "[+]database ok\n[-]cache failed: reason withheld\nhealth check failed\n", rr.Body.String())
+
+	// Invalid status codes are rejected when the option is built rather than
+	// failing every request.
+	assert.Panics(t, func() { WithStatusCode(StatusUp, 0) })
+	assert.Panics(t, func() { WithStatusCode(StatusDown, 600) })
+}
+
+func TestHealth_ActuatorFormat(t *testing.T) {
+	h := New(
+		Component{Name: "db", Critical: true, Check: func(ctx context.Context) error { return nil }},
+		Component{Name: "redis", Check: func(ctx context.Context) error { return nil }},
+		Component{Name: "ping", Groups: []string{GroupLiveness}, Check: func(ctx context.Context) error { return nil }},
+	)
+	h.components[0].record(Result{Status: StatusUp, Details: map[string]any{"database": "PostgreSQL"}, Latency: 2 * time.Millisecond}, time.Now(), 2*time.Millisecond)
+	h.components[1].record(Result{Status: StatusDown, Error: errors.New("connection refused")}, time.Now(), time.Millisecond)
+	h.components[2].setStatus(StatusUp)
+
+	authorized := func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer secret"
+	}
+	handler := h.Handler(
+		WithActuatorFormat(ShowDetailsWhenAuthorized(authorized)),
+		WithComponentRoutes("/actuator/health"),
+	)
+	get := func(target string, authorized bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if authorized {
+			r.Header.Set("Authorization", "Bearer secret")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	rr := get("/actuator/health", false)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"DEGRADED","groups":["liveness","readiness"]}`, rr.Body.String())
+
+	rr = get("/actuator/health", true)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res struct {
+		Status     Status `json:"status"`
+		Components map[string]struct {
+			Status  Status         `json:"status"`
+			Details map[string]any `json:"details"`
+		} `json:"components"`
+		Groups []string `json:"groups"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, StatusDegraded, res.Status)
+	assert.Len(t, res.Components, 3)
+	assert.Equal(t, StatusUp, res.Components["db"].Status)
+	assert.Equal(t, "PostgreSQL", res.Components["db"].Details["database"])
+	assert.Equal(t, "2ms", res.Components["db"].Details["latency"])
+	assert.Equal(t, StatusDown, res.Components["redis"].Status)
+	assert.Equal(t, "connection refused", res.Components["redis"].Details["error"])
+	assert.Nil(t, res.Components["ping"].Details)
+	assert.Equal(t, []string{"liveness", "readiness"}, res.Groups)
+
+	// Groups are served beneath the base path without listing the groups.
+	rr = get("/actuator/health/liveness", true)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"UP","components":{"ping":{"status":"UP"}}}`, rr.Body.String())
+
+	rr = get("/actuator/health/readiness", false)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"DEGRADED"}`, rr.Body.String())
+
+	rr = get("/actuator/health/redis", true)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	var component struct {
+		Status  Status         `json:"status"`
+		Details map[string]any `json:"details"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &component))
+	assert.Equal(t, StatusDown, component.Status)
+	assert.Equal(t, "connection refused", component.Details["error"])
+	assert.Contains(t, component.Details, "lastChecked")
+
+	rr = get("/actuator/health/kafka", true)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// The actuator media type is returned when requested.
+	r := httptest.NewRequest(http.MethodGet, "/actuator/health", nil)
+	r.Header.Set("Accept", "application/vnd.spring-boot.actuator.v3+json")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+	assert.Equal(t, "application/vnd.spring-boot.actuator.v3+json", rr.Header().Get("Content-Type"))
+
+	// Without a ShowDetails the components are never shown.
+	rr = httptest.NewRecorder()
+	h.Handler(WithActuatorFormat(nil)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/actuator/health", nil))
+	assert.JSONEq(t, `{"status":"DEGRADED","groups":["liveness","readiness"]}`, rr.Body.String())
+}
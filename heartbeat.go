@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// HeartbeatComponent is a component acting as a dead man's switch for a part of
+// the application, such as a batch loop or scheduled job, which regularly calls
+// Beat on the returned Heartbeat. If a beat isn't received within the configured
+// windows the component is considered degraded, and then down.
+type HeartbeatComponent struct {
+
+	// Name or identifier of the component. Each component must have a unique,
+	// non-empty name.
+	Name string
+
+	// Determines if the component is critical to the overall health and
+	// functionality of the system. If a component is marked as critical, and
+	// it's down, the overall status of the system will be down.
+	Critical bool
+
+	// Severity of the component being unavailable, see Component.Severity.
+	Severity Severity
+
+	// If set, the component is considered degraded when no beat has been
+	// received within this duration. Must be less than DownAfter.
+	DegradedAfter time.Duration
+
+	// The component is considered down when no beat has been received within
+	// this duration. DownAfter is required.
+	DownAfter time.Duration
+
+	// Interval between evaluations of the time since the last beat. The default
+	// is half of DegradedAfter, or DownAfter if DegradedAfter isn't set.
+	Interval time.Duration
+}
+
+// Heartbeat receives the beats of a heartbeat component. It is safe for
+// concurrent use.
+type Heartbeat struct {
+	degradedAfter time.Duration
+	downAfter     time.Duration
+
+	// last is the time of the last beat, or the time the component was
+	// registered if no beat has been received, in Unix nanoseconds.
+	last  atomic.Int64
+	beats atomic.Int64
+}
+
+// RegisterHeartbeat registers a heartbeat component and returns the Heartbeat
+// on which the application calls Beat. The time since the last beat, or since
+// the component was registered if no beat has been received, is evaluated at
+// every Interval like any other component.
+//
+// Returns a *ValidationError if the component doesn't have a name, a component
+// with the same name is already registered, DownAfter is not positive, or
+// DegradedAfter is not less than DownAfter.
+func (h *Health) RegisterHeartbeat(hc HeartbeatComponent) (*Heartbeat, error) {
+	if hc.DownAfter <= 0 || hc.DegradedAfter < 0 || (hc.DegradedAfter > 0 && hc.DegradedAfter >= hc.DownAfter) {
+		return nil, &ValidationError{
+			Component: hc.Name,
+			Err:       fmt.Errorf("%w: down after must be positive and greater than degraded after", ErrInvalidConfig),
+		}
+	}
+
+	hb := &Heartbeat{
+		degradedAfter: hc.DegradedAfter,
+		downAfter:     hc.DownAfter,
+	}
+	hb.last.Store(time.Now().UnixNano())
+
+	interval := hc.Interval
+	if interval == 0 {
+		interval = hc.DownAfter / 2
+		if hc.DegradedAfter > 0 {
+			interval = hc.DegradedAfter / 2
+		}
+	}
+	err := h.TryRegister(Component{
+		Name:        hc.Name,
+		Critical:    hc.Critical,
+		Severity:    hc.Severity,
+		Interval:    interval,
+		Timeout:     interval / 2,
+		ResultCheck: hb.check,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hb, nil
+}
+
+// Beat records that the monitored part of the application is alive.
+func (hb *Heartbeat) Beat() {
+	hb.last.Store(time.Now().UnixNano())
+	hb.beats.Add(1)
+}
+
+// check reports the status of the component from the time since the last beat.
+func (hb *Heartbeat) check(ctx context.Context) Result {
+	last := time.Unix(0, hb.last.Load())
+	since := time.Since(last)
+
+	details := map[string]any{
+		"since_last_beat": since.String(),
+	}
+	if hb.beats.Load() > 0 {
+		details["last_beat"] = last
+	}
+
+	switch {
+	case since >= hb.downAfter:
+		return Result{
+			Status:  StatusDown,
+			Message: fmt.Sprintf("no heartbeat received within %s", hb.downAfter),
+			Details: details,
+		}
+	case hb.degradedAfter > 0 && since >= hb.degradedAfter:
+		return Result{
+			Status:  StatusDegraded,
+			Message: fmt.Sprintf("no heartbeat received within %s", hb.degradedAfter),
+			Details: details,
+		}
+	default:
+		return Result{
+			Status:  StatusUp,
+			Details: details,
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// discardHandler is a slog.Handler that discards all records. It is used as the
+// default so the library is silent unless a logger is configured.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+var discardLogger = slog.New(discardHandler{})
+
+// logResult logs the result of a check of the component. Transitions between
+// statuses are logged at a level corresponding to the new status, while failed
+// checks that don't change the status are logged at the repeated failure level.
+func (c *Component) logResult(previous, current Status, result Result, duration time.Duration) {
+	logger := c.logger
+	if logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("component", c.Name),
+		slog.Bool("critical", c.severity == SeverityCritical),
+		slog.String("severity", string(c.severity)),
+		slog.Duration("duration", duration),
+	}
+	if result.Error != nil {
+		attrs = append(attrs,
+			slog.String("error", result.Error.Error()),
+			slog.String("failure", string(failureReason(result))))
+	}
+	var panicErr *PanicError
+	if errors.As(result.Error, &panicErr) {
+		attrs = append(attrs, slog.String("stack", string(panicErr.Stack)))
+	}
+
+	ctx := context.Background()
+	if previous != current {
+		level := slog.LevelWarn
+		switch current {
+		case StatusUp:
+			level = slog.LevelInfo
+		case StatusDown:
+			level = slog.LevelError
+		}
+		attrs = append(attrs,
+			slog.String("previous_status", string(previous)),
+			slog.String("status", string(current)))
+		logger.LogAttrs(ctx, level, "health: component status changed", attrs...)
+		return
+	}
+	if result.Status == StatusDown {
+		attrs = append(attrs, slog.String("status", string(current)))
+		logger.LogAttrs(ctx, c.repeatedFailureLevel, "health: component check failed", attrs...)
+	}
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Option configures a Health instance created by NewWithOptions.
+type Option func(*Health)
+
+// WithComponents registers the provided components with the Health instance.
+//
+// Panics if any of the components are invalid, see Health.TryRegister.
+func WithComponents(components ...Component) Option {
+	return func(h *Health) {
+		for _, c := range components {
+			h.Register(c)
+		}
+	}
+}
+
+// WithLogger configures the logger used to emit structured logs when components
+// are registered, change status, fail their checks, and when Health is shut
+// down. By default, nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(h *Health) {
+		if logger != nil {
+			h.logger = logger
+		}
+	}
+}
+
+// WithRepeatedFailureLevel configures the level at which a failed check that
+// doesn't change the status of the component is logged, such as each check of a
+// component that is already down. The default level is slog.LevelDebug to avoid
+// flooding the logs during an extended outage.
+func WithRepeatedFailureLevel(level slog.Level) Option {
+	return func(h *Health) {
+		h.repeatedFailureLevel = level
+	}
+}
+
+// WithOnDemandChecks configures the Health instance to check components when
+// their status is requested by Snapshot, Status, or the HTTP handler, rather than
+// periodically in the background. This avoids checking dependencies of services
+// that are rarely probed, and ensures the status reflects the state of the
+// components at the time of the request.
+//
+// The components are checked in parallel. The result of each check is reused for
+// the CacheTTL of the component, and concurrent requests share a single check of
+// each component so probes don't multiply the load on dependencies. If timeout
+// is positive, requests wait at most timeout for the checks to complete, with
+// components that haven't completed their check reporting their last known
+// status.
+//
+// As with background checks, components are only checked on demand between
+// Start and Shutdown.
+func WithOnDemandChecks(timeout time.Duration) Option {
+	return func(h *Health) {
+		h.onDemand = true
+		h.onDemandTimeout = timeout
+	}
+}
+
+// WithAggregator configures the Aggregator used to determine the overall status
+// of the application from the status of each component. The default is
+// DefaultAggregator.
+func WithAggregator(aggregator Aggregator) Option {
+	return func(h *Health) {
+		if aggregator != nil {
+			h.aggregator = aggregator
+		}
+	}
+}
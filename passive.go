@@ -0,0 +1,171 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PassiveComponent is a component whose health cannot be checked by polling, so
+// its status is instead set by the application, such as when a circuit breaker
+// opens, a Kafka consumer is fenced, or a background worker crashes.
+type PassiveComponent struct {
+
+	// Name or identifier of the component. Each component must have a unique,
+	// non-empty name.
+	Name string
+
+	// Determines if the component is critical to the overall health and
+	// functionality of the system. If a component is marked as critical, and
+	// it's down, the overall status of the system will be down.
+	Critical bool
+
+	// Severity of the component being unavailable, see Component.Severity.
+	Severity Severity
+
+	// Status of the component until the application sets the status. The
+	// default value is StatusUnknown.
+	InitialStatus Status
+
+	// If set, the status of the component reverts to the ExpiredStatus if the
+	// status isn't set again within the TTL. This can be used to detect when the
+	// part of the application responsible for reporting the status stops doing
+	// so.
+	TTL time.Duration
+
+	// Status of the component once the TTL elapses without the status being set.
+	// The default value is StatusUnknown.
+	ExpiredStatus Status
+}
+
+// PassiveHandle is used by the application to set the status of a passive
+// component. It is safe for concurrent use.
+type PassiveHandle struct {
+	component *Component
+	ttl       time.Duration
+	expired   Status
+
+	mu         sync.Mutex
+	timer      *time.Timer
+	generation uint64
+}
+
+// RegisterPassive registers a passive component and returns the handle used to
+// set its status. Unlike components registered with Register, a passive
+// component is never checked and its status only changes when set through the
+// handle, or when its TTL elapses.
+//
+// Returns a *ValidationError if the component doesn't have a name, a component
+// with the same name is already registered, its TTL is negative, or its initial
+// or expired status is not registered.
+func (h *Health) RegisterPassive(pc PassiveComponent) (*PassiveHandle, error) {
+	handle := &PassiveHandle{
+		ttl:     pc.TTL,
+		expired: pc.ExpiredStatus,
+	}
+	if handle.expired == "" {
+		handle.expired = StatusUnknown
+	}
+	if pc.TTL < 0 {
+		return nil, &ValidationError{
+			Component: pc.Name,
+			Err:       fmt.Errorf("%w: ttl must not be negative", ErrInvalidConfig),
+		}
+	}
+	for _, status := range []Status{pc.InitialStatus, handle.expired} {
+		if status != "" && !status.Valid() {
+			return nil, &ValidationError{
+				Component: pc.Name,
+				Err:       fmt.Errorf("%w: %w %q", ErrInvalidConfig, ErrInvalidStatus, status),
+			}
+		}
+	}
+
+	component := &Component{
+		Name:     pc.Name,
+		Critical: pc.Critical,
+		Severity: pc.Severity,
+		passive:  handle,
+	}
+	if err := component.init(); err != nil {
+		return nil, err
+	}
+	handle.component = component
+	if pc.InitialStatus != "" {
+		component.setStatus(pc.InitialStatus)
+	}
+
+	if err := h.add(component); err != nil {
+		return nil, err
+	}
+	if pc.InitialStatus != "" {
+		handle.mu.Lock()
+		handle.arm()
+		handle.mu.Unlock()
+	}
+	return handle, nil
+}
+
+// SetUp sets the status of the component to up.
+func (p *PassiveHandle) SetUp() {
+	p.SetResult(Result{Status: StatusUp})
+}
+
+// SetDown sets the status of the component to down with the error that caused
+// the component to be down.
+func (p *PassiveHandle) SetDown(err error) {
+	p.SetResult(Result{Status: StatusDown, Error: err})
+}
+
+// SetStatus sets the status of the component. A status that has not been
+// registered sets the status of the component to down with an error wrapping
+// ErrInvalidStatus.
+func (p *PassiveHandle) SetStatus(status Status) {
+	p.SetResult(Result{Status: status})
+}
+
+// SetResult sets the status of the component along with a message and details
+// about the state of the component. If the Status of the Result is empty the
+// component is considered up.
+func (p *PassiveHandle) SetResult(result Result) {
+	if result.Status == "" {
+		result.Status = StatusUp
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.component.report(result, time.Now(), 0)
+	p.arm()
+}
+
+// arm starts, or restarts, the TTL of the status. The caller must hold p.mu.
+func (p *PassiveHandle) arm() {
+	if p.ttl <= 0 {
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	// The generation guards against a timer that fired concurrently with the
+	// status being set from expiring the newly set status.
+	p.generation++
+	generation := p.generation
+	p.timer = time.AfterFunc(p.ttl, func() {
+		p.expire(generation)
+	})
+}
+
+// expire reverts the status of the component to the expired status.
+func (p *PassiveHandle) expire(generation uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if generation != p.generation {
+		return
+	}
+	p.component.report(Result{
+		Status:  p.expired,
+		Message: fmt.Sprintf("status not set within %s", p.ttl),
+	}, time.Now(), 0)
+}
@@ -0,0 +1,92 @@
+package health
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Probe groups corresponding to the Kubernetes liveness, readiness, and startup
+// probes. Components opt into groups using the Groups field, with components
+// that don't specify any groups belonging to the readiness group.
+const (
+	// GroupLiveness contains the components that indicate the application is
+	// unable to recover and must be restarted. Dependencies shared by many
+	// instances, such as a database, generally don't belong in this group since
+	// an outage would cause every instance to be restarted.
+	GroupLiveness = "liveness"
+
+	// GroupReadiness contains the components the application requires to serve
+	// traffic.
+	GroupReadiness = "readiness"
+
+	// GroupStartup contains the components that must be up before the
+	// application has started, such as a database migration.
+	GroupStartup = "startup"
+)
+
+// inGroup returns true if the component belongs to the group.
+func (c *Component) inGroup(group string) bool {
+	if len(c.Groups) == 0 {
+		return group == GroupReadiness
+	}
+	return slices.Contains(c.Groups, group)
+}
+
+// groups returns the groups the component belongs to.
+func (cs ComponentStatus) groups() []string {
+	if len(cs.Groups) == 0 {
+		return []string{GroupReadiness}
+	}
+	return cs.Groups
+}
+
+// Group returns the components belonging to the group. If group is empty every
+// component is returned.
+func (c Components) Group(group string) Components {
+	if group == "" {
+		return c
+	}
+	return c.filter(func(component *Component) bool {
+		return component.inGroup(group)
+	})
+}
+
+// WithGroup restricts the handler to the components belonging to the group. A
+// group without any components is reported as up.
+func WithGroup(group string) HandlerOption {
+	return func(conf *handlerConfig) {
+		conf.group = group
+	}
+}
+
+// LivenessHandler returns an http.Handler for a Kubernetes liveness probe which
+// reports the status of the components in the liveness group.
+func (h *Health) LivenessHandler(opts ...HandlerOption) http.Handler {
+	return h.Handler(append(opts, WithGroup(GroupLiveness))...)
+}
+
+// ReadinessHandler returns an http.Handler for a Kubernetes readiness probe
+// which reports the status of the components in the readiness group. Once
+// Shutdown has been called the readiness probe fails so traffic is drained from
+// the instance.
+func (h *Health) ReadinessHandler(opts ...HandlerOption) http.Handler {
+	return h.Handler(append(opts, WithGroup(GroupReadiness))...)
+}
+
+// StartupHandler returns an http.Handler for a Kubernetes startup probe which
+// reports the status of the components in the startup group. Once the startup
+// group has been up the startup probe always succeeds, since Kubernetes stops
+// probing once the startup probe succeeds.
+func (h *Health) StartupHandler(opts ...HandlerOption) http.Handler {
+	return h.Handler(append(opts, WithGroup(GroupStartup))...)
+}
+
+// RegisterProbes registers the handlers for the Kubernetes liveness, readiness,
+// and startup probes with the ServeMux at /livez, /readyz, and /startupz
+// respectively. The components are checked once regardless of how many groups
+// they belong to.
+func (h *Health) RegisterProbes(mux *http.ServeMux, opts ...HandlerOption) {
+	mux.Handle("/livez", h.LivenessHandler(opts...))
+	mux.Handle("/readyz", h.ReadinessHandler(opts...))
+	mux.Handle("/startupz", h.StartupHandler(opts...))
+}
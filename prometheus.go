@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -14,6 +15,23 @@ import (
 //
 // The status of each component is exposed as a gauge named "health_component_status"
 // with a value of 0 for down, 1 for up.
+//
+// The component gauges are labeled with the name of the component and its
+// severity.
+//
+// The latency observed by the most recent check of each component is exposed as
+// a gauge named "health_component_latency_seconds". Components that have not
+// completed a check do not report a latency.
+//
+// An unknown status, such as before a component has completed its first check,
+// is reported as 0 since the application or component cannot be assumed to be
+// available. The value reported for any other status, including custom statuses,
+// is configured by the PrometheusValue of the status, see RegisterStatus. A
+// component is reported as 1 only if the value of its status is at least the
+// value of up.
+//
+// If the Health instance checks components on demand, see WithOnDemandChecks,
+// the components are checked when the metrics are collected.
 func EnablePrometheus(h *Health) error {
 	overallStatus := prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: "health",
@@ -24,50 +42,60 @@ func EnablePrometheus(h *Health) error {
 		Namespace: "health",
 		Name:      "component_status",
 		Help:      "Indicator of status of the application components. 0 is down, 1 is up",
-	}, []string{"component"})
+	}, []string{"component", "severity"})
+	componentLatency := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "health",
+		Name:      "component_latency_seconds",
+		Help:      "Latency observed by the most recent health check of the application components.",
+	}, []string{"component", "severity"})
 
 	c := &collector{
-		components: h.components,
-		overall:    overallStatus,
-		component:  componentStatus,
+		health:    h,
+		overall:   overallStatus,
+		component: componentStatus,
+		latency:   componentLatency,
 	}
 	return prometheus.Register(c)
 }
 
 type collector struct {
-	components Components
-	overall    prometheus.Gauge
-	component  *prometheus.GaugeVec
+	mu        sync.Mutex
+	health    *Health
+	overall   prometheus.Gauge
+	component *prometheus.GaugeVec
+	latency   *prometheus.GaugeVec
 }
 
-func (c collector) Describe(descs chan<- *prometheus.Desc) {
+func (c *collector) Describe(descs chan<- *prometheus.Desc) {
 	c.overall.Describe(descs)
 	c.component.Describe(descs)
+	c.latency.Describe(descs)
 }
 
-func (c collector) Collect(metrics chan<- prometheus.Metric) {
-	overall := c.components.Status(context.Background())
-	switch overall {
-	case StatusDown:
-		c.overall.Set(0)
-	case StatusDegraded:
-		c.overall.Set(1)
-	case StatusUp:
-		c.overall.Set(2)
-	}
+func (c *collector) Collect(metrics chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	componentStatuses := c.components.ComponentStatus(context.Background())
-	for _, status := range componentStatuses {
-		switch status.Status {
-		case StatusDown:
-			c.component.WithLabelValues(status.Name).Set(0)
-		case StatusDegraded:
-			c.component.WithLabelValues(status.Name).Set(0)
-		case StatusUp:
-			c.component.WithLabelValues(status.Name).Set(1)
+	snapshot := c.health.Snapshot(context.Background())
+	c.overall.Set(snapshot.Status.prometheusValue())
+
+	// Reset the series of the components so components that have been
+	// deregistered are no longer exported.
+	c.component.Reset()
+	c.latency.Reset()
+	for _, status := range snapshot.Components {
+		severity := string(status.severity())
+		if status.Status.prometheusValue() >= StatusUp.prometheusValue() {
+			c.component.WithLabelValues(status.Name, severity).Set(1)
+		} else {
+			c.component.WithLabelValues(status.Name, severity).Set(0)
+		}
+		if status.Latency > 0 {
+			c.latency.WithLabelValues(status.Name, severity).Set(status.Latency.Seconds())
 		}
 	}
 
 	c.overall.Collect(metrics)
 	c.component.Collect(metrics)
+	c.latency.Collect(metrics)
 }
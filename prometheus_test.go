@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
@@ -105,13 +106,35 @@ func TestEnablePrometheus(t *testing.T) {
 			for i := 0; i < len(hc.components); i++ {
 				err := hc.components[i].Check(context.Background())
 				if err == nil {
-					hc.components[i].status = StatusUp
+					hc.components[i].setStatus(StatusUp)
 				} else {
-					hc.components[i].status = StatusDown
+					hc.components[i].setStatus(StatusDown)
 				}
 			}
 			err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(tt.expected), "health_status")
 			assert.NoError(t, err)
 		})
 	}
+	t.Run("Component Latency", func(t *testing.T) {
+		hc.components[0].record(Result{Status: StatusUp, Latency: 250 * time.Millisecond}, time.Now(), 0)
+		hc.components[1].record(Result{Status: StatusDown, Latency: 2 * time.Second}, time.Now(), 0)
+		expected := `
+			# HELP health_component_latency_seconds Latency observed by the most recent health check of the application components.
+			# TYPE health_component_latency_seconds gauge
+			health_component_latency_seconds{component="mongo",severity="critical"} 2
+			health_component_latency_seconds{component="redis",severity="major"} 0.25
+		`
+		err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "health_component_latency_seconds")
+		assert.NoError(t, err)
+	})
+	t.Run("Deregistered Component", func(t *testing.T) {
+		assert.NoError(t, hc.Deregister("redis"))
+		expected := `
+			# HELP health_component_status Indicator of status of the application components. 0 is down, 1 is up
+			# TYPE health_component_status gauge
+			health_component_status{component="mongo",severity="critical"} 0
+		`
+		err := testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "health_component_status")
+		assert.NoError(t, err)
+	})
 }
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"fmt"
+)
+
+// Severity describes the impact of a component being unavailable on the overall
+// health of the application.
+//
+// With the DefaultAggregator, a critical component that is down takes the
+// overall status down, a major or minor component that is down degrades the
+// overall status, and an informational component never affects the overall
+// status. Major and minor components are otherwise aggregated the same, but the
+// severity is included in the status of the component and the Prometheus metrics
+// so alerts can distinguish them.
+type Severity string
+
+const (
+	// SeverityCritical indicates the application cannot function without the
+	// component. Setting Critical on a component is equivalent to this severity.
+	SeverityCritical Severity = "critical"
+
+	// SeverityMajor indicates the application is significantly impaired without
+	// the component. This is the default severity of components that are not
+	// critical.
+	SeverityMajor Severity = "major"
+
+	// SeverityMinor indicates the user experience is degraded without the
+	// component.
+	SeverityMinor Severity = "minor"
+
+	// SeverityInfo indicates the component is reported for informational
+	// purposes only and never affects the overall status.
+	SeverityInfo Severity = "info"
+)
+
+// valid returns true if the severity is one of the defined severities.
+func (s Severity) valid() bool {
+	switch s {
+	case SeverityCritical, SeverityMajor, SeverityMinor, SeverityInfo:
+		return true
+	default:
+		return false
+	}
+}
+
+// resolveSeverity determines the severity of a component from its Severity and
+// Critical fields, which must agree if both are set.
+func resolveSeverity(severity Severity, critical bool) (Severity, error) {
+	switch {
+	case severity == "" && critical:
+		return SeverityCritical, nil
+	case severity == "":
+		return SeverityMajor, nil
+	case !severity.valid():
+		return "", fmt.Errorf("%w: unknown severity %q", ErrInvalidConfig, severity)
+	case critical && severity != SeverityCritical:
+		return "", fmt.Errorf("%w: critical component cannot have severity %q", ErrInvalidConfig, severity)
+	default:
+		return severity, nil
+	}
+}
+
+// severity returns the severity of the component, deriving it from Critical if
+// the Severity isn't set, such as for a ComponentStatus constructed by hand.
+func (cs ComponentStatus) severity() Severity {
+	if cs.Severity != "" {
+		return cs.Severity
+	}
+	if cs.Critical {
+		return SeverityCritical
+	}
+	return SeverityMajor
+}
@@ -0,0 +1,33 @@
+package health
+
+import (
+	"time"
+)
+
+// Snapshot is an immutable point-in-time view of the health of the application.
+//
+// The overall Status is derived from the exact same component statuses contained
+// in Components, so a Snapshot is always internally consistent even while the
+// components are concurrently being checked. Each call to Snapshot returns new
+// copies, so callers are free to retain or modify the returned value.
+type Snapshot struct {
+	// Overall status of the application.
+	Status Status `json:"status"`
+
+	// Time the snapshot was taken.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Status of each component at the time the snapshot was taken.
+	Components []ComponentStatus `json:"components"`
+}
+
+// Component returns the status of the component with the given name and true,
+// or the zero value and false if the snapshot does not contain the component.
+func (s Snapshot) Component(name string) (ComponentStatus, bool) {
+	for _, c := range s.Components {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ComponentStatus{}, false
+}
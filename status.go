@@ -1,11 +1,18 @@
 package health
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strings"
+	"sync"
 )
 
 // Status represents the status of the application.
+//
+// In addition to the statuses defined by this package, custom statuses can be
+// registered with RegisterStatus.
 type Status string
 
 const (
@@ -17,23 +24,174 @@ const (
 	// StatusDown indicates the application is not functional and for all intents
 	// and purposes the application is down (not usable).
 	StatusDown Status = "DOWN"
+	// StatusUnknown indicates the status has not yet been determined, such as
+	// when a component has not yet completed its first health check.
+	StatusUnknown Status = "UNKNOWN"
+	// StatusOutOfService indicates the application or component has been taken
+	// out of service deliberately and should not be used.
+	StatusOutOfService Status = "OUT_OF_SERVICE"
+	// StatusMaintenance indicates the application or component is undergoing
+	// planned maintenance.
+	StatusMaintenance Status = "MAINTENANCE"
 )
 
-// HttpStatusCode returns the HTTP status code for the given status.
-func (s Status) HttpStatusCode() int {
-	switch s {
-	case StatusUp:
-		return http.StatusOK
-	case StatusDegraded:
+// ErrInvalidStatus is returned when parsing or registering a status that is not
+// valid, such as a status that has not been registered.
+var ErrInvalidStatus = errors.New("health: invalid status")
+
+// StatusConfig configures how a Status is reported and aggregated.
+type StatusConfig struct {
+	// HTTP status code of the health endpoint while the overall status is the
+	// Status.
+	HTTPStatusCode int
+
+	// Severity of the Status used to order statuses during aggregation, where
+	// the status with the highest severity is the worst. The built-in statuses
+	// have the severities 0 for UP, 100 for DEGRADED, 200 for MAINTENANCE, 300
+	// for UNKNOWN, 400 for OUT_OF_SERVICE and 500 for DOWN, leaving room for
+	// custom statuses between them.
+	Severity int
+
+	// Value of the Prometheus gauge reporting the overall status while the
+	// overall status is the Status. By convention 0 is down, 1 is degraded,
+	// and 2 is up.
+	PrometheusValue float64
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[Status]StatusConfig{
+		StatusUp: {
+			HTTPStatusCode:  http.StatusOK,
+			Severity:        0,
+			PrometheusValue: 2,
+		},
 		// Although the service is degraded we still need to return an HTTP 2XX
 		// status or Kubernetes will not consider the service available and not
 		// route traffic to it.
-		return http.StatusOK
-	case StatusDown:
+		StatusDegraded: {
+			HTTPStatusCode:  http.StatusOK,
+			Severity:        100,
+			PrometheusValue: 1,
+		},
+		StatusMaintenance: {
+			HTTPStatusCode:  http.StatusServiceUnavailable,
+			Severity:        200,
+			PrometheusValue: 0,
+		},
+		// Until the status is known the service cannot be assumed to be able
+		// to handle traffic.
+		StatusUnknown: {
+			HTTPStatusCode:  http.StatusServiceUnavailable,
+			Severity:        300,
+			PrometheusValue: 0,
+		},
+		StatusOutOfService: {
+			HTTPStatusCode:  http.StatusServiceUnavailable,
+			Severity:        400,
+			PrometheusValue: 0,
+		},
+		StatusDown: {
+			HTTPStatusCode:  http.StatusServiceUnavailable,
+			Severity:        500,
+			PrometheusValue: 0,
+		},
+	}
+)
+
+// RegisterStatus registers a custom Status, or reconfigures an existing Status,
+// so it can be reported by checks and parsed by ParseStatus. Statuses are
+// registered globally, so RegisterStatus should be called during initialization
+// of the application.
+//
+// Returns an error wrapping ErrInvalidStatus if the status is empty or not in
+// upper case, or the HTTP status code is not valid.
+func RegisterStatus(status Status, conf StatusConfig) error {
+	if status == "" || strings.ToUpper(string(status)) != string(status) {
+		return fmt.Errorf("%w %q: must be a non-empty upper case string", ErrInvalidStatus, status)
+	}
+	if conf.HTTPStatusCode < 100 || conf.HTTPStatusCode > 599 {
+		return fmt.Errorf("%w %q: invalid http status code %d", ErrInvalidStatus, status, conf.HTTPStatusCode)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[status] = conf
+	return nil
+}
+
+// LookupStatus returns the configuration of the Status and true, or the zero
+// value and false if the Status has not been registered.
+func LookupStatus(status Status) (StatusConfig, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	conf, ok := registry[status]
+	return conf, ok
+}
+
+// ParseStatus parses a registered Status, ignoring case.
+//
+// Returns an error wrapping ErrInvalidStatus if the status is not registered.
+func ParseStatus(s string) (Status, error) {
+	status := Status(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := LookupStatus(status); !ok {
+		return "", fmt.Errorf("%w %q", ErrInvalidStatus, s)
+	}
+	return status, nil
+}
+
+// Valid returns true if the Status has been registered.
+func (s Status) Valid() bool {
+	_, ok := LookupStatus(s)
+	return ok
+}
+
+// HttpStatusCode returns the HTTP status code for the given status. A status
+// that has not been registered is reported as 503 Service Unavailable, since the
+// application cannot be assumed to be able to handle traffic.
+func (s Status) HttpStatusCode() int {
+	conf, ok := LookupStatus(s)
+	if !ok {
 		return http.StatusServiceUnavailable
-	default:
-		// This can only happen by a programming error or someone trying to
-		// skirt around the Status type and constants defined.
-		panic(fmt.Errorf("%s is not a valid status", s))
 	}
+	return conf.HTTPStatusCode
+}
+
+// severity returns the severity of the status. A status that has not been
+// registered is considered the most severe.
+func (s Status) severity() int {
+	conf, ok := LookupStatus(s)
+	if !ok {
+		return math.MaxInt
+	}
+	return conf.Severity
+}
+
+// prometheusValue returns the value of the Prometheus gauge reporting the
+// status. A status that has not been registered is reported as 0.
+func (s Status) prometheusValue() float64 {
+	conf, _ := LookupStatus(s)
+	return conf.PrometheusValue
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The status is
+// marshalled as is, even if it is not registered, so encoding the status of
+// the application never fails.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, parsing the
+// text with ParseStatus. An empty string is unmarshalled as the zero value.
+func (s *Status) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = ""
+		return nil
+	}
+	status, err := ParseStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
 }
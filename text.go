@@ -0,0 +1,108 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WithTextOutput serves the health endpoint in the plain-text format of the
+// Kubernetes API server health endpoints rather than JSON. If the overall status
+// is up or degraded the response is "ok", otherwise, or if the query parameter
+// verbose is present, the response lists the status of each component followed
+// by whether the check passed.
+//
+//	[+]database ok
+//	[-]cache failed: connection refused
+//	readiness check failed
+//
+// As with JSON responses, the query parameter exclude can be repeated to ignore
+// the named components, such as during an incident.
+func WithTextOutput() HandlerOption {
+	return func(conf *handlerConfig) {
+		conf.encoder = textEncoder{}
+	}
+}
+
+// textEncoder writes the responses of the health endpoint as plain text.
+type textEncoder struct{}
+
+// encodeSnapshot writes the Snapshot as the plain-text response of the health
+// endpoint.
+func (textEncoder) encodeSnapshot(w http.ResponseWriter, r *http.Request, snapshot Snapshot, conf handlerConfig) {
+	passed := conf.passing(snapshot.Status)
+	if passed && !r.URL.Query().Has("verbose") {
+		writeText(w, r, snapshot.Status, conf, "ok\n")
+		return
+	}
+
+	var b strings.Builder
+	for _, component := range snapshot.Components {
+		b.WriteString(textLine(component, conf))
+		b.WriteByte('\n')
+	}
+	name := conf.group
+	if name == "" {
+		name = "health"
+	}
+	if passed {
+		fmt.Fprintf(&b, "%s check passed\n", name)
+	} else {
+		fmt.Fprintf(&b, "%s check failed\n", name)
+	}
+	writeText(w, r, snapshot.Status, conf, b.String())
+}
+
+// encodeComponent writes the status of a single component as the plain-text
+// response of the health endpoint.
+func (textEncoder) encodeComponent(w http.ResponseWriter, r *http.Request, status ComponentStatus, conf handlerConfig) {
+	if conf.passing(status.Status) && !r.URL.Query().Has("verbose") {
+		writeText(w, r, status.Status, conf, "ok\n")
+		return
+	}
+	writeText(w, r, status.Status, conf, textLine(status, conf)+"\n")
+}
+
+// textLine formats the status of the component as a line of the plain-text
+// response.
+func textLine(status ComponentStatus, conf handlerConfig) string {
+	reason := status.Error
+	if reason == "" {
+		reason = status.Message
+	}
+	if conf.redactErrors && reason != "" {
+		reason = "reason withheld"
+	}
+
+	switch status.Status {
+	case StatusUp:
+		return fmt.Sprintf("[+]%s ok", status.Name)
+	case StatusDegraded:
+		if reason == "" {
+			return fmt.Sprintf("[+]%s degraded", status.Name)
+		}
+		return fmt.Sprintf("[+]%s degraded: %s", status.Name, reason)
+	case StatusDown:
+		if reason == "" {
+			reason = "reason withheld"
+		}
+		return fmt.Sprintf("[-]%s failed: %s", status.Name, reason)
+	default:
+		return fmt.Sprintf("[-]%s %s", status.Name, strings.ToLower(string(status.Status)))
+	}
+}
+
+// passing returns true if the status is considered to pass a health check,
+// which is when the status is reported with a successful HTTP status code.
+func (conf handlerConfig) passing(status Status) bool {
+	return conf.statusCode(status) < http.StatusBadRequest
+}
+
+// writeText writes the plain-text response of the health endpoint.
+func writeText(w http.ResponseWriter, r *http.Request, status Status, conf handlerConfig, body string) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !conf.writeHeader(w, r, status, "text/plain;charset=utf-8") {
+		return
+	}
+	_, _ = w.Write([]byte(body))
+}